@@ -0,0 +1,157 @@
+package gent
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// requestBody is the content of a request's body created by a RequestBuilder.
+type requestBody struct {
+	reader  io.Reader
+	length  int64
+	getBody func() (io.ReadCloser, error)
+	headers map[string][]string
+	custom  bool
+}
+
+// content creates the body of the request from the body, marshaler, stream
+// marshaler or reader of the request builder.
+func (rb *RequestBuilder) content() (requestBody, error) {
+	switch {
+	case rb.streamer != nil:
+		enc, hdrs, err := rb.streamer(rb.body)
+		if err != nil {
+			return requestBody{}, err
+		}
+		return requestBody{
+			reader: newPipeBody(enc),
+			length: -1,
+			getBody: func() (io.ReadCloser, error) {
+				return newPipeBody(enc), nil
+			},
+			headers: hdrs,
+			custom:  true,
+		}, nil
+
+	case rb.bodyReader != nil:
+		return readerContent(rb.bodyReader)
+
+	case rb.marshaler != nil:
+		return marshalContent(rb.body, rb.marshaler)
+	}
+
+	if raw, ok := rb.body.([]byte); raw != nil && ok {
+		return requestBody{reader: bytes.NewReader(raw)}, nil
+	} else if rb.body != nil {
+		marshaler := rb.negotiate()
+		if marshaler == nil {
+			return requestBody{}, ErrInvalidBodyType
+		}
+		return marshalContent(rb.body, marshaler)
+	}
+	return requestBody{reader: http.NoBody}, nil
+}
+
+// marshalContent creates the body of a request by marshaling an object.
+func marshalContent(body any, marshaler Marshaler) (requestBody, error) {
+	dat, hdrs, err := marshaler(body)
+	if err != nil {
+		return requestBody{}, err
+	}
+	return requestBody{reader: bytes.NewReader(dat), headers: hdrs}, nil
+}
+
+// readerContent creates the body of a request from a reader. Readers that are
+// supported by http.NewRequest are used as they are. The length of seekers
+// and readers with a Len method is known, and seekers can be replayed by
+// seeking back to their current offset. Seekers are never closed.
+func readerContent(r io.Reader) (requestBody, error) {
+	switch r.(type) {
+	case *bytes.Buffer, *bytes.Reader, *strings.Reader:
+		return requestBody{reader: r}, nil
+	}
+
+	if rs, ok := r.(io.ReadSeeker); ok {
+		start, err := rs.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return requestBody{}, err
+		}
+		end, err := rs.Seek(0, io.SeekEnd)
+		if err != nil {
+			return requestBody{}, err
+		}
+		if _, err = rs.Seek(start, io.SeekStart); err != nil {
+			return requestBody{}, err
+		}
+
+		length := end - start
+		return requestBody{
+			reader: io.NopCloser(io.LimitReader(rs, length)),
+			length: length,
+			getBody: func() (io.ReadCloser, error) {
+				if _, err := rs.Seek(start, io.SeekStart); err != nil {
+					return nil, err
+				}
+				return io.NopCloser(io.LimitReader(rs, length)), nil
+			},
+			custom: true,
+		}, nil
+	}
+
+	if lr, ok := r.(interface{ Len() int }); ok {
+		return requestBody{reader: r, length: int64(lr.Len()), custom: true}, nil
+	}
+	return requestBody{reader: r, length: -1, custom: true}, nil
+}
+
+// apply sets the length and the function to replay the body on the request
+// for bodies that http.NewRequest can not inspect.
+func (b *requestBody) apply(req *http.Request) {
+	if !b.custom {
+		return
+	}
+
+	req.ContentLength = b.length
+	req.GetBody = b.getBody
+	if b.length == 0 {
+		req.Body = http.NoBody
+		req.GetBody = func() (io.ReadCloser, error) {
+			return http.NoBody, nil
+		}
+	}
+}
+
+// pipeBody is a request body that encodes content into a pipe. Encoding
+// starts in a separate goroutine on the first read, so bodies that are closed
+// without being read do not leak goroutines.
+type pipeBody struct {
+	once sync.Once
+	enc  func(io.Writer) error
+	pr   *io.PipeReader
+	pw   *io.PipeWriter
+}
+
+// newPipeBody creates a body that encodes content into a pipe.
+func newPipeBody(enc func(io.Writer) error) *pipeBody {
+	pr, pw := io.Pipe()
+	return &pipeBody{enc: enc, pr: pr, pw: pw}
+}
+
+// Read reads encoded content from the pipe, and starts encoding on the
+// first call.
+func (b *pipeBody) Read(p []byte) (int, error) {
+	b.once.Do(func() {
+		go func() {
+			b.pw.CloseWithError(b.enc(b.pw))
+		}()
+	})
+	return b.pr.Read(p)
+}
+
+// Close closes the pipe, which stops encoding the content.
+func (b *pipeBody) Close() error {
+	return b.pr.Close()
+}
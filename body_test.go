@@ -0,0 +1,186 @@
+package gent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRequestBodyReader tests building requests with reader bodies.
+func TestRequestBodyReader(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "upload.txt")
+	os.WriteFile(file, []byte("UserUpdated"), 0o600)
+
+	tests := []struct {
+		Name   string
+		Reader func() io.Reader
+		Length int64
+		Body   []byte
+		Replay bool
+	}{
+		{
+			Name:   "Bytes reader",
+			Reader: func() io.Reader { return bytes.NewReader([]byte("UserUpdated")) },
+			Length: 11,
+			Body:   []byte("UserUpdated"),
+			Replay: true,
+		},
+		{
+			Name:   "Strings reader",
+			Reader: func() io.Reader { return strings.NewReader("UserUpdated") },
+			Length: 11,
+			Body:   []byte("UserUpdated"),
+			Replay: true,
+		},
+		{
+			Name: "File",
+			Reader: func() io.Reader {
+				f, _ := os.Open(file)
+				t.Cleanup(func() { f.Close() })
+				return f
+			},
+			Length: 11,
+			Body:   []byte("UserUpdated"),
+			Replay: true,
+		},
+		{
+			Name: "Seeker from offset",
+			Reader: func() io.Reader {
+				f, _ := os.Open(file)
+				t.Cleanup(func() { f.Close() })
+				f.Seek(4, io.SeekStart)
+				return f
+			},
+			Length: 7,
+			Body:   []byte("Updated"),
+			Replay: true,
+		},
+		{
+			Name: "Reader with unknown length",
+			Reader: func() io.Reader {
+				return io.MultiReader(strings.NewReader("User"), strings.NewReader("Updated"))
+			},
+			Length: -1,
+			Body:   []byte("UserUpdated"),
+			Replay: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := NewRequest(
+				http.MethodPost, "http://localhost:8080/events",
+			).WithBodyReader(
+				test.Reader(),
+			).Build(context.Background())
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Length, req.ContentLength)
+			body, _ := io.ReadAll(req.Body)
+			assert.Equal(t, test.Body, body)
+
+			if test.Replay && assert.NotNil(t, req.GetBody) {
+				rc, err := req.GetBody()
+				assert.Nil(t, err)
+				body, _ := io.ReadAll(rc)
+				assert.Equal(t, test.Body, body)
+			} else if !test.Replay {
+				assert.Nil(t, req.GetBody)
+			}
+		})
+	}
+}
+
+// TestRequestBodyStream tests building requests with streamed bodies.
+func TestRequestBodyStream(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Body      any
+		Marshaler StreamMarshaler
+		Content   []byte
+		Headers   http.Header
+		Error     error
+	}{
+		{
+			Name:      "Json stream",
+			Body:      map[string]any{"name": "John Smith"},
+			Marshaler: JsonStreamMarshaler,
+			Content:   []byte("{\"name\":\"John Smith\"}\n"),
+			Headers:   http.Header{"Content-Type": {"application/json"}},
+		},
+		{
+			Name: "Stream marshaler fails",
+			Body: map[string]any{"name": "John Smith"},
+			Marshaler: func(any) (func(io.Writer) error, map[string][]string, error) {
+				return nil, nil, ErrInvalidBodyType
+			},
+			Error: ErrInvalidBodyType,
+		},
+		{
+			Name: "Encoding fails",
+			Body: map[string]any{"name": "John Smith"},
+			Marshaler: func(any) (func(io.Writer) error, map[string][]string, error) {
+				return func(w io.Writer) error {
+					w.Write([]byte("{"))
+					return errors.New("failed")
+				}, nil, nil
+			},
+			Content: []byte("{"),
+			Headers: http.Header{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := NewRequest(
+				http.MethodPost, "http://localhost:8080/users",
+			).WithBodyStream(
+				test.Body, test.Marshaler,
+			).Build(context.Background())
+
+			assert.Equal(t, test.Error, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, int64(-1), req.ContentLength)
+			assert.Equal(t, test.Headers, req.Header)
+			for i := 0; i < 2; i++ {
+				body, _ := io.ReadAll(req.Body)
+				assert.Equal(t, test.Content, body)
+				req.Body, _ = req.GetBody()
+			}
+		})
+	}
+}
+
+// TestPipeBodyClose tests closing a streamed body before it was read.
+func TestPipeBodyClose(t *testing.T) {
+	tests := []struct {
+		Name string
+	}{
+		{Name: "Close unread body"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			body := newPipeBody(func(w io.Writer) error {
+				_, err := w.Write([]byte("UserUpdated"))
+				return err
+			})
+
+			body.Close()
+			_, err := body.Read(make([]byte, 1))
+
+			assert.Equal(t, io.ErrClosedPipe, err)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package gent
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by Client.Do when the circuit breaker of the
+// request is open, and the request was not sent.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerOptions configures the behavior of the CircuitBreaker middleware.
+type CircuitBreakerOptions struct {
+	// FailureThreshold is the number of consecutive failed requests that
+	// open the breaker. Defaults to 5.
+	FailureThreshold int
+
+	// SuccessThreshold is the number of successful requests in the half-open
+	// state that close the breaker. Defaults to 1.
+	SuccessThreshold int
+
+	// OpenTimeout is how long the breaker stays open before it lets requests
+	// through in the half-open state. Defaults to 30 seconds.
+	OpenTimeout time.Duration
+
+	// Key optionally returns the key that identifies the breaker of a request.
+	// Defaults to the host of the request's URL.
+	Key func(*Context) string
+
+	// IsFailure optionally reports whether a request failed. Defaults to
+	// requests that added errors to the context, or have a response with a
+	// 5xx status code.
+	IsFailure func(*Context) bool
+}
+
+// breakerState is the state of a circuit breaker.
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+// breaker tracks the state of a single circuit. The generation changes with
+// every state transition, so that outcomes of requests allowed in an earlier
+// state are not applied to the current state.
+type breaker struct {
+	mtx        sync.Mutex
+	state      breakerState
+	generation uint64
+	failures   int
+	successes  int
+	probes     int
+	openedAt   time.Time
+}
+
+// breakerTicket identifies a request that was allowed through a breaker.
+type breakerTicket struct {
+	generation uint64
+	probe      bool
+}
+
+// CircuitBreaker creates a middleware that stops sending requests while
+// they keep failing. Breakers are closed by default, and open after too many
+// consecutive failures, failing requests fast with ErrCircuitOpen. After a
+// timeout, breakers become half-open and let a probe request through, which
+// closes the breaker on success or opens it again on failure.
+func CircuitBreaker(opts CircuitBreakerOptions) func(*Context) {
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 5
+	}
+	if opts.SuccessThreshold <= 0 {
+		opts.SuccessThreshold = 1
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = 30 * time.Second
+	}
+	if opts.Key == nil {
+		opts.Key = func(ctx *Context) string {
+			return ctx.Request.URL.Host
+		}
+	}
+
+	mtx := sync.Mutex{}
+	breakers := map[string]*breaker{}
+
+	return func(ctx *Context) {
+		key := opts.Key(ctx)
+		mtx.Lock()
+		br, ok := breakers[key]
+		if !ok {
+			br = &breaker{}
+			breakers[key] = br
+		}
+		mtx.Unlock()
+
+		ticket, ok := br.allow(opts.OpenTimeout)
+		if !ok {
+			ctx.AbortWithError(ErrCircuitOpen)
+			return
+		}
+
+		errc := len(ctx.Errors)
+		ctx.Next()
+
+		var failed bool
+		if opts.IsFailure != nil {
+			failed = opts.IsFailure(ctx)
+		} else {
+			failed = len(ctx.Errors) > errc ||
+				(ctx.Response != nil &&
+					ctx.Response.StatusCode >= http.StatusInternalServerError)
+		}
+		br.record(ticket, failed, &opts)
+	}
+}
+
+// allow reports whether a request can be sent through the breaker, and
+// returns the ticket of the request for recording its outcome. Open breakers
+// become half-open after the timeout, when a single request is allowed at a
+// time to probe the circuit.
+func (br *breaker) allow(timeout time.Duration) (breakerTicket, bool) {
+	br.mtx.Lock()
+	defer br.mtx.Unlock()
+
+	if br.state == breakerOpen && time.Since(br.openedAt) >= timeout {
+		br.transition(breakerHalfOpen)
+		br.successes = 0
+		br.probes = 0
+	}
+
+	switch br.state {
+	case breakerClosed:
+		return breakerTicket{generation: br.generation}, true
+	case breakerHalfOpen:
+		if br.probes == 0 {
+			br.probes++
+			return breakerTicket{generation: br.generation, probe: true}, true
+		}
+	}
+	return breakerTicket{}, false
+}
+
+// record updates the state of the breaker with the outcome of a request.
+// Outcomes of requests that were allowed before the last state transition
+// are ignored.
+func (br *breaker) record(
+	ticket breakerTicket,
+	failed bool,
+	opts *CircuitBreakerOptions,
+) {
+	br.mtx.Lock()
+	defer br.mtx.Unlock()
+
+	if ticket.generation != br.generation {
+		return
+	}
+
+	switch br.state {
+	case breakerClosed:
+		if !failed {
+			br.failures = 0
+		} else if br.failures++; br.failures >= opts.FailureThreshold {
+			br.open()
+		}
+	case breakerHalfOpen:
+		if ticket.probe {
+			br.probes--
+		}
+		if failed {
+			br.open()
+		} else if br.successes++; br.successes >= opts.SuccessThreshold {
+			br.transition(breakerClosed)
+			br.failures = 0
+		}
+	}
+}
+
+// open changes the state of the breaker to open.
+func (br *breaker) open() {
+	br.transition(breakerOpen)
+	br.openedAt = time.Now()
+	br.failures = 0
+}
+
+// transition changes the state of the breaker and starts a new generation.
+func (br *breaker) transition(state breakerState) {
+	br.state = state
+	br.generation++
+}
@@ -0,0 +1,188 @@
+package gent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCircuitBreaker tests opening, half-opening and closing the breaker.
+func TestCircuitBreaker(t *testing.T) {
+	errFailed := fmt.Errorf("failed")
+
+	tests := []struct {
+		Name      string
+		Requester *mockRequester
+		Requests  int
+		Sent      int
+		Errors    []error
+	}{
+		{
+			Name:      "Successful requests",
+			Requester: &mockRequester{StatusCode: 200},
+			Requests:  4,
+			Sent:      4,
+			Errors:    []error{nil, nil, nil, nil},
+		},
+		{
+			Name:      "Opens on failed status codes",
+			Requester: &mockRequester{StatusCode: 500},
+			Requests:  4,
+			Sent:      2,
+			Errors:    []error{nil, nil, ErrCircuitOpen, ErrCircuitOpen},
+		},
+		{
+			Name:      "Opens on transport errors",
+			Requester: &mockRequester{RequestErr: errFailed},
+			Requests:  3,
+			Sent:      2,
+			Errors:    []error{errFailed, errFailed, ErrCircuitOpen},
+		},
+		{
+			Name:      "Successes reset failures",
+			Requester: &mockRequester{StatusCodes: []int{500, 200, 500, 200}},
+			Requests:  4,
+			Sent:      4,
+			Errors:    []error{nil, nil, nil, nil},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cl := NewClient(test.Requester)
+			cl.Use(CircuitBreaker(CircuitBreakerOptions{
+				FailureThreshold: 2,
+				OpenTimeout:      time.Hour,
+			}))
+
+			for i := 0; i < test.Requests; i++ {
+				_, err := cl.Get("http://localhost:8080")
+				assert.ErrorIs(t, err, test.Errors[i])
+			}
+			assert.Equal(t, test.Sent, test.Requester.CountCalled)
+		})
+	}
+}
+
+// TestCircuitBreakerHalfOpen tests recovering from the open state.
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Status int
+		Error  error
+	}{
+		{
+			Name:   "Probe succeeds",
+			Status: 200,
+			Error:  nil,
+		},
+		{
+			Name:   "Probe fails",
+			Status: 503,
+			Error:  ErrCircuitOpen,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{StatusCode: 500}
+			cl := NewClient(mock)
+			cl.Use(CircuitBreaker(CircuitBreakerOptions{
+				FailureThreshold: 1,
+				OpenTimeout:      10 * time.Millisecond,
+			}))
+
+			cl.Get("http://localhost:8080")
+			_, err := cl.Get("http://localhost:8080")
+			assert.True(t, errors.Is(err, ErrCircuitOpen))
+
+			time.Sleep(20 * time.Millisecond)
+			mock.StatusCode = test.Status
+			_, err = cl.Get("http://localhost:8080")
+			assert.Nil(t, err)
+
+			_, err = cl.Get("http://localhost:8080")
+			assert.ErrorIs(t, err, test.Error)
+		})
+	}
+}
+
+// TestCircuitBreakerKey tests that breakers are separated by key.
+func TestCircuitBreakerKey(t *testing.T) {
+	tests := []struct {
+		Name string
+	}{
+		{Name: "Breakers per host"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{StatusCode: 500}
+			cl := NewClient(mock)
+			cl.Use(CircuitBreaker(CircuitBreakerOptions{
+				FailureThreshold: 1,
+				OpenTimeout:      time.Hour,
+			}))
+
+			cl.Get("http://service-a:8080")
+			_, errA := cl.Get("http://service-a:8080")
+			_, errB := cl.Get("http://service-b:8080")
+
+			assert.ErrorIs(t, errA, ErrCircuitOpen)
+			assert.Nil(t, errB)
+		})
+	}
+}
+
+// TestCircuitBreakerStaleOutcome tests that outcomes of requests allowed
+// before a state transition do not change the current state.
+func TestCircuitBreakerStaleOutcome(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Failed bool
+	}{
+		{
+			Name:   "Stale success while half-open",
+			Failed: false,
+		},
+		{
+			Name:   "Stale failure while half-open",
+			Failed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			opts := CircuitBreakerOptions{
+				FailureThreshold: 1,
+				SuccessThreshold: 3,
+				OpenTimeout:      10 * time.Millisecond,
+			}
+			br := &breaker{}
+
+			slow, ok := br.allow(opts.OpenTimeout)
+			assert.True(t, ok)
+			failed, _ := br.allow(opts.OpenTimeout)
+			br.record(failed, true, &opts)
+			_, ok = br.allow(opts.OpenTimeout)
+			assert.False(t, ok)
+
+			time.Sleep(20 * time.Millisecond)
+			probe, ok := br.allow(opts.OpenTimeout)
+			assert.True(t, ok)
+			assert.True(t, probe.probe)
+
+			br.record(slow, test.Failed, &opts)
+			br.record(probe, false, &opts)
+
+			_, ok = br.allow(opts.OpenTimeout)
+			assert.True(t, ok)
+			assert.Equal(t, breakerHalfOpen, br.state)
+			assert.Equal(t, 1, br.successes)
+			assert.Equal(t, 1, br.probes)
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Package brotligent provides the brotli content encoding for gent.
+package brotligent
+
+import (
+	"io"
+
+	"github.com/Soreing/gent"
+	"github.com/andybalholm/brotli"
+)
+
+// Name is the name of the brotli content encoding.
+const Name = "br"
+
+// Encoding compresses and decompresses bodies with brotli. Register it with
+// gent.RegisterEncoding under Name.
+var Encoding = gent.Encoding{
+	Compress: func(w io.Writer) (io.WriteCloser, error) {
+		return brotli.NewWriter(w), nil
+	},
+	Decompress: func(r io.Reader) (io.ReadCloser, error) {
+		return io.NopCloser(brotli.NewReader(r)), nil
+	},
+}
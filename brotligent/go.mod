@@ -0,0 +1,15 @@
+module github.com/Soreing/gent/brotligent
+
+go 1.24
+
+require (
+	github.com/Soreing/gent v1.1.0
+	github.com/andybalholm/brotli v1.2.0
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
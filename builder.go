@@ -1,11 +1,12 @@
 package gent
 
 import (
-	"bytes"
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 )
 
 // ErrInvalidBodyType is returned by Marshaler functions when the object
@@ -13,21 +14,149 @@ import (
 // when the body requires a marshaler but none is provided
 var ErrInvalidBodyType = errors.New("invalid body type")
 
+// ErrNoClient is returned by RequestBuilder.Send when the request builder was
+// not created by a Client.
+var ErrNoClient = errors.New("request builder has no client")
+
 // ErrInvalidFormat is returned by RequestBuilder.Build when the format has a
 // trailing or incomplate placeholder {}, or if the number of placeholders does
-// not match the number of path parameters
+// not match the number of path parameters. Errors of named placeholders wrap
+// ErrInvalidFormat with the name of the missing or unused parameter.
 var ErrInvalidFormat = errors.New("invalid endpoint format")
 
+// requestMetaKey is the context key of the details of a request that were
+// set by a RequestBuilder but are not part of an *http.Request.
+type requestMetaKey struct{}
+
+// requestMeta stores details of a request that were set by a RequestBuilder.
+// It is the context of the request, which wraps the context the request was
+// built with, so the details are kept by copies of the request and requests
+// with contexts derived from it. When a client sends the request, the details
+// are marked as sent for the requests of its middleware chain. Requests that
+// are sent with contexts derived from a sent request's context, such as from
+// a middleware, do not inherit the details.
+type requestMeta struct {
+	context.Context
+	*requestOptions
+
+	sent   bool
+	format string
+}
+
+// requestOptions stores the options of a request that were set by a
+// RequestBuilder, which are only allocated when any of them are set.
+type requestOptions struct {
+	accepted    []StatusRange
+	values      []KeyValue
+	middlewares []func(*Context)
+	options     []ClientOption
+}
+
+// noRequestOptions is the options of requests that have none set.
+var noRequestOptions = &requestOptions{}
+
+// unsentMeta is the details of requests that are sent by a client without
+// details of their own.
+var unsentMeta = &requestMeta{requestOptions: noRequestOptions, sent: true}
+
+// Value returns the details for the requestMetaKey, or the value of the key
+// in the wrapped context.
+func (meta *requestMeta) Value(key any) any {
+	if key == (requestMetaKey{}) {
+		return meta
+	}
+	return meta.Context.Value(key)
+}
+
+// getRequestMeta returns the details of the request set by a RequestBuilder.
+func getRequestMeta(req *http.Request) *requestMeta {
+	meta, _ := req.Context().Value(requestMetaKey{}).(*requestMeta)
+	return meta
+}
+
+// sendRequestMeta returns the details of a request that is about to be sent
+// by a client, and a shallow copy of the request with the details marked as
+// sent. Details that were already sent with another request are replaced, so
+// they do not apply to the request or the requests of its middlewares.
+func sendRequestMeta(req *http.Request) (*http.Request, *requestMeta) {
+	meta, _ := req.Context().Value(requestMetaKey{}).(*requestMeta)
+	if meta == nil {
+		return req, unsentMeta
+	}
+
+	sent := &requestMeta{requestOptions: noRequestOptions, sent: true}
+	if !meta.sent {
+		sent.requestOptions = meta.requestOptions
+		sent.format = meta.format
+	}
+	sent.Context = req.Context()
+	return req.WithContext(sent), sent
+}
+
+// RequestFormat returns the format that a RequestBuilder used to build the
+// request, such as "/users/{}/devices", which can be used as a low cardinality
+// route template. It returns an empty string if the request was not built
+// by a RequestBuilder.
+func RequestFormat(req *http.Request) string {
+	if meta := getRequestMeta(req); meta != nil {
+		return meta.format
+	}
+	return ""
+}
+
+// RequestRoute returns the path of the format that a RequestBuilder used to
+// build the request without the scheme, host and query, such as
+// "/users/{}/devices". It returns an empty string if the request was not built
+// by a RequestBuilder.
+func RequestRoute(req *http.Request) string {
+	format := RequestFormat(req)
+	if format == "" {
+		return ""
+	}
+
+	if _, rest, ok := strings.Cut(format, "://"); ok {
+		if i := strings.IndexByte(rest, '/'); i >= 0 {
+			format = rest[i:]
+		} else {
+			format = "/"
+		}
+	}
+	if i := strings.IndexAny(format, "?#"); i >= 0 {
+		format = format[:i]
+	}
+	return format
+}
+
+// pathTemplate returns the route of the request, or the path of the request's
+// URL if it was not built by a RequestBuilder.
+func pathTemplate(req *http.Request) string {
+	if route := RequestRoute(req); route != "" {
+		return route
+	}
+	return req.URL.Path
+}
+
 // RequestBuilder allows gradual creation of http requests with functions to
 // attach a body, headers, query parameters and path parameters.
 type RequestBuilder struct {
-	method    string
-	format    string
-	body      any
-	marshaler Marshaler
-	headers   map[string][]string
-	queryPrms map[string][]string
-	pathPrms  []string
+	method      string
+	format      string
+	endpoint    *Endpoint
+	body        any
+	marshaler   Marshaler
+	streamer    StreamMarshaler
+	bodyReader  io.Reader
+	headers     map[string][]string
+	queryPrms   map[string][]string
+	pathPrms    []string
+	namedPrms   map[string]string
+	accepted    []StatusRange
+	unmarshaler Unmarshaler
+	compression *CompressionOptions
+	values      []KeyValue
+	middlewares []func(*Context)
+	options     []ClientOption
+	client      *Client
 }
 
 // NewRequest creates a request builder.
@@ -47,19 +176,67 @@ func (rb *RequestBuilder) WithRawBody(
 	body []byte,
 ) *RequestBuilder {
 	rb.marshaler = nil
+	rb.streamer = nil
+	rb.bodyReader = nil
 	rb.body = body
 	return rb
 }
 
 // WithBody adds a body and a marshaler to the request. If a body or marshaler
 // is already set, it will overwrite it. The headers returned by the marshaler
-// will not overwrite headers set by [WithHeader] or [WithHeaders]
+// will not overwrite headers set by [WithHeader]. If the marshaler is nil,
+// the body is encoded with the marshaler in the client's registry matching
+// the Content-Type header of the request, or the client's default content type.
 func (rb *RequestBuilder) WithBody(
 	body any,
 	marshaler Marshaler,
 ) *RequestBuilder {
 	rb.body = body
 	rb.marshaler = marshaler
+	rb.streamer = nil
+	rb.bodyReader = nil
+	return rb
+}
+
+// WithBodyStream adds a body and a stream marshaler to the request, which
+// encodes the body while the request is being sent instead of buffering it.
+// The body is sent with chunked transfer encoding, and is encoded again if the
+// request needs to be replayed. If a body or marshaler is already set, it will
+// overwrite it.
+func (rb *RequestBuilder) WithBodyStream(
+	body any,
+	marshaler StreamMarshaler,
+) *RequestBuilder {
+	rb.body = body
+	rb.marshaler = nil
+	rb.streamer = marshaler
+	rb.bodyReader = nil
+	return rb
+}
+
+// WithBodyReader sets a reader as the request body, which is streamed while
+// the request is being sent. The Content-Length is set when the length of the
+// reader is known, and io.ReadSeekers such as files can be replayed by seeking
+// back to their current offset. Readers are not closed by the request. If a
+// body or marshaler is already set, it will overwrite it.
+func (rb *RequestBuilder) WithBodyReader(
+	body io.Reader,
+) *RequestBuilder {
+	rb.body = nil
+	rb.marshaler = nil
+	rb.streamer = nil
+	rb.bodyReader = body
+	return rb
+}
+
+// WithCompression compresses the body of the request with a content encoding
+// while it is being sent, and sets the Content-Encoding header. Bodies smaller
+// than the minimum size of the options are sent as they are.
+func (rb *RequestBuilder) WithCompression(
+	opts CompressionOptions,
+) *RequestBuilder {
+	opts = opts.defaults()
+	rb.compression = &opts
 	return rb
 }
 
@@ -105,58 +282,212 @@ func (rb *RequestBuilder) WithPathParameters(
 	return rb
 }
 
+// WithPathParameter adds a named path parameter to the request. The parameter
+// gets escaped and replaces the {name} placeholder in the request endpoint.
+// If there was already a parameter set with the same name, it will overwrite it.
+func (rb *RequestBuilder) WithPathParameter(
+	name string,
+	val string,
+) *RequestBuilder {
+	if rb.namedPrms == nil {
+		rb.namedPrms = map[string]string{}
+	}
+	rb.namedPrms[name] = url.PathEscape(val)
+	return rb
+}
+
+// WithAcceptedStatus sets the ranges of status codes that are accepted by the
+// StatusCheck middleware for the request, overriding the client's ranges.
+func (rb *RequestBuilder) WithAcceptedStatus(
+	ranges ...StatusRange,
+) *RequestBuilder {
+	rb.accepted = append(rb.accepted, ranges...)
+	return rb
+}
+
+// WithValues sets values of typed keys in the context of the request when it
+// is sent by a client, before any middleware runs.
+func (rb *RequestBuilder) WithValues(
+	vals ...KeyValue,
+) *RequestBuilder {
+	rb.values = append(rb.values, vals...)
+	return rb
+}
+
+// WithMiddleware adds middleware style handler functions that only run for
+// the request. They run in the order they were added after the middlewares of
+// the client, and before the client performs the request.
+func (rb *RequestBuilder) WithMiddleware(
+	middlewares ...func(*Context),
+) *RequestBuilder {
+	rb.middlewares = append(rb.middlewares, middlewares...)
+	return rb
+}
+
+// WithClientOptions sets options that override the options of the client for
+// the request, such as default headers or the registry of marshalers. Default
+// headers and query parameters replace the client's values of the same keys.
+// The client itself is not changed.
+func (rb *RequestBuilder) WithClientOptions(
+	opts ...ClientOption,
+) *RequestBuilder {
+	rb.options = append(rb.options, opts...)
+	return rb
+}
+
+// WithUnmarshaler sets the unmarshaler that decodes the response body in
+// [SendAndDecode], instead of picking one from the Content-Type header.
+func (rb *RequestBuilder) WithUnmarshaler(
+	unmarshaler Unmarshaler,
+) *RequestBuilder {
+	rb.unmarshaler = unmarshaler
+	return rb
+}
+
+// Send builds the request and sends it with the client that created the
+// request builder. It returns ErrNoClient if the request builder was not
+// created by a Client.
+func (rb *RequestBuilder) Send(
+	ctx context.Context,
+) (res *http.Response, err error) {
+	_, res, err = rb.send(ctx, "")
+	return res, err
+}
+
+// SendAndDecode sends the request like [Send] and decodes the response body
+// into v with the request builder's unmarshaler, or the one in the client's
+// registry matching the response's Content-Type header. Without an unmarshaler,
+// an Accept header is generated from the registry if the request has none.
+// The body is drained and closed. Responses with non-2xx status codes return
+// an HTTPError.
+func (rb *RequestBuilder) SendAndDecode(
+	ctx context.Context,
+	v any,
+) error {
+	accept := ""
+	if rb.unmarshaler == nil && rb.client != nil {
+		accept = rb.client.with(rb.options...).codecs().Accept()
+	}
+
+	req, res, err := rb.send(ctx, accept)
+	if err != nil {
+		if res != nil && res.Body != nil {
+			res.Body.Close()
+		}
+		return err
+	}
+
+	unmarshal := rb.unmarshaler
+	if unmarshal == nil {
+		unmarshal = rb.client.with(rb.options...).codecs().
+			Unmarshaler(res.Header.Get("Content-Type"))
+	}
+	return decodeResponse(req, res, v, unmarshal)
+}
+
+// send builds the request and sends it with the client that created the
+// request builder. The Accept header is set if it is not empty and the
+// request has none.
+func (rb *RequestBuilder) send(
+	ctx context.Context,
+	accept string,
+) (req *http.Request, res *http.Response, err error) {
+	if rb.client == nil {
+		return nil, nil, ErrNoClient
+	}
+
+	req, err = rb.Build(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if accept != "" && req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	res, err = rb.client.Do(req)
+	return req, res, err
+}
+
+// negotiate returns the marshaler in the client's registry, or the default
+// registry, that matches the Content-Type header of the request or the
+// client's default content type.
+func (rb *RequestBuilder) negotiate() Marshaler {
+	reg, ctype := DefaultRegistry, ""
+	if rb.client != nil {
+		cl := rb.client.with(rb.options...)
+		reg, ctype = cl.codecs(), cl.contentType
+	}
+	if vals := rb.header("Content-Type"); len(vals) > 0 {
+		ctype = vals[0]
+	}
+
+	if ctype == "" {
+		return nil
+	}
+	return reg.Marshaler(ctype)
+}
+
+// header returns the values of a header set on the request builder, where
+// the key is case insensitive.
+func (rb *RequestBuilder) header(key string) []string {
+	for k, vals := range rb.headers {
+		if strings.EqualFold(k, key) {
+			return vals
+		}
+	}
+	return nil
+}
+
 // Build returns a *http.Request from the values of the request builder.
 func (rb *RequestBuilder) Build(
 	ctx context.Context,
 ) (res *http.Request, err error) {
-	buflen := len(rb.format)
-	for _, param := range rb.pathPrms {
-		buflen += len(param) - 2
-	}
-
-	// create request endpoint
-	endp := make([]byte, 0, buflen)
-	open, cursor, pidx := false, 0, 0
-	for i, ch := range rb.format {
-		if (open && ch != '}') || (!open && ch == '}') {
-			return nil, ErrInvalidFormat
-		} else if ch == '{' && pidx == len(rb.pathPrms) {
-			return nil, ErrInvalidFormat
-		} else if ch == '{' {
-			open = true
-		} else if ch == '}' {
-			open = false
-			endp = append(endp, rb.format[cursor:i-1]...)
-			endp = append(endp, rb.pathPrms[pidx]...)
-			cursor = i + 1
-			pidx++
+	// create request endpoint, compiling the format on the stack for
+	// formats with few placeholders
+	ep := rb.endpoint
+	if ep == nil {
+		var literals, names [8]string
+		compiled, err := compileFormat(rb.format, literals[:0], names[:0])
+		if err != nil {
+			return nil, err
 		}
+		ep = &compiled
 	}
-	if open || pidx != len(rb.pathPrms) {
-		return nil, ErrInvalidFormat
+	endp, err := ep.expand(rb.pathPrms, rb.namedPrms)
+	if err != nil {
+		return nil, err
 	}
-	endp = append(endp, rb.format[cursor:]...)
 
 	// create body content
-	var body []byte
-	var bodyHdrs map[string][]string
-	if rb.marshaler != nil {
-		body, bodyHdrs, err = rb.marshaler(rb.body)
-		if err != nil {
-			return nil, err
-		}
-	} else if bytes, ok := rb.body.([]byte); bytes != nil && ok {
-		body = bytes
-	} else if rb.body != nil {
-		return nil, ErrInvalidBodyType
+	body, err := rb.content()
+	if err != nil {
+		return nil, err
 	}
 
 	// create request
-	reader := bytes.NewReader(body)
-	req, err := http.NewRequestWithContext(ctx, rb.method, string(endp), reader)
+	meta := &requestMeta{
+		Context:        ctx,
+		requestOptions: noRequestOptions,
+		format:         rb.format,
+	}
+	if rb.accepted != nil || rb.values != nil ||
+		rb.middlewares != nil || rb.options != nil {
+		meta.requestOptions = &requestOptions{
+			accepted:    rb.accepted,
+			values:      rb.values,
+			middlewares: rb.middlewares,
+			options:     rb.options,
+		}
+	}
+	if ctx != nil {
+		// nil contexts are rejected by http.NewRequestWithContext
+		ctx = meta
+	}
+	req, err := http.NewRequestWithContext(ctx, rb.method, endp, body.reader)
 	if err != nil {
 		return nil, err
 	}
+	body.apply(req)
 
 	// set query params
 	if req.URL.RawQuery == "" {
@@ -175,11 +506,21 @@ func (rb *RequestBuilder) Build(
 			req.Header.Add(key, val)
 		}
 	}
-	for key, vals := range bodyHdrs {
+	for key, vals := range body.headers {
+		if rb.header(key) != nil {
+			continue
+		}
 		for _, val := range vals {
 			req.Header.Add(key, val)
 		}
 	}
 
+	// compress body
+	if rb.compression != nil && compressible(req, *rb.compression) {
+		if err := compressRequest(req, *rb.compression); err != nil {
+			return nil, err
+		}
+	}
+
 	return req, nil
 }
@@ -2,10 +2,12 @@ package gent
 
 import (
 	"context"
+	"encoding/xml"
 	"errors"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -109,6 +111,76 @@ func TestRequestWithBody(t *testing.T) {
 	}
 }
 
+// TestRequestWithBodyStream tests adding a body and stream marshaler to a
+// request builder.
+func TestRequestWithBodyStream(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Builder *RequestBuilder
+		Body    any
+	}{
+		{
+			Name:    "Adding new request body",
+			Builder: &RequestBuilder{},
+			Body:    map[string]any{"Name": "John Smith"},
+		},
+		{
+			Name: "Overwriting existing request body",
+			Builder: &RequestBuilder{
+				body:       "placeholder",
+				marshaler:  XmlMarshaler,
+				bodyReader: strings.NewReader("placeholder"),
+			},
+			Body: map[string]any{"Name": "John Smith"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req := test.Builder.WithBodyStream(test.Body, JsonStreamMarshaler)
+
+			assert.Equal(t, test.Body, req.body)
+			assert.NotNil(t, req.streamer)
+			assert.Nil(t, req.marshaler)
+			assert.Nil(t, req.bodyReader)
+		})
+	}
+}
+
+// TestRequestWithBodyReader tests adding a reader body to a request builder.
+func TestRequestWithBodyReader(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Builder *RequestBuilder
+		Reader  io.Reader
+	}{
+		{
+			Name:    "Adding new request body",
+			Builder: &RequestBuilder{},
+			Reader:  strings.NewReader("UserUpdated"),
+		},
+		{
+			Name: "Overwriting existing request body",
+			Builder: &RequestBuilder{
+				body:      map[string]any{},
+				marshaler: JsonMarshaler,
+			},
+			Reader: strings.NewReader("UserUpdated"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req := test.Builder.WithBodyReader(test.Reader)
+
+			assert.Equal(t, test.Reader, req.bodyReader)
+			assert.Nil(t, req.body)
+			assert.Nil(t, req.marshaler)
+			assert.Nil(t, req.streamer)
+		})
+	}
+}
+
 // TestRequestWithHeader tests adding headers to a request builder.
 func TestRequestWithHeader(t *testing.T) {
 	tests := []struct {
@@ -295,8 +367,170 @@ func TestRequestWithPathParameters(t *testing.T) {
 	}
 }
 
+// TestRequestWithPathParameter tests adding named path parameters to a
+// request builder.
+func TestRequestWithPathParameter(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Builder *RequestBuilder
+		Added   [][2]string
+		After   map[string]string
+	}{
+		{
+			Name:    "Adding parameters to empty set",
+			Builder: &RequestBuilder{},
+			Added:   [][2]string{{"userId", "123"}, {"orderId", "456"}},
+			After:   map[string]string{"userId": "123", "orderId": "456"},
+		},
+		{
+			Name: "Overwriting existing parameter",
+			Builder: &RequestBuilder{
+				namedPrms: map[string]string{"userId": "123"},
+			},
+			Added: [][2]string{{"userId", "789"}},
+			After: map[string]string{"userId": "789"},
+		},
+		{
+			Name:    "Adding parameters to be escaped",
+			Builder: &RequestBuilder{},
+			Added:   [][2]string{{"name", "Hello, Wold!"}},
+			After:   map[string]string{"name": "Hello%2C%20Wold%21"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+
+			for _, prm := range test.Added {
+				test.Builder.WithPathParameter(prm[0], prm[1])
+			}
+
+			assert.Equal(t, test.After, test.Builder.namedPrms)
+		})
+	}
+}
+
+// TestRequestBuildNamed tests building a request with named placeholders.
+func TestRequestBuildNamed(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Format string
+		Named  map[string]string
+		Params []string
+		Path   string
+		Error  string
+	}{
+		{
+			Name:   "Named placeholders",
+			Format: "https://localhost:8080/users/{userId}/orders/{order_id}",
+			Named:  map[string]string{"userId": "123", "order_id": "a b"},
+			Path:   "/users/123/orders/a b",
+		},
+		{
+			Name:   "Named placeholder used twice",
+			Format: "https://localhost:8080/{id}/{id}",
+			Named:  map[string]string{"id": "123"},
+			Path:   "/123/123",
+		},
+		{
+			Name:   "Missing named parameter",
+			Format: "https://localhost:8080/users/{userId}/orders/{orderId}",
+			Named:  map[string]string{"userId": "123"},
+			Error:  "invalid endpoint format: missing path parameter for placeholder {orderId}",
+		},
+		{
+			Name:   "Unused named parameter",
+			Format: "https://localhost:8080/users/{userId}",
+			Named:  map[string]string{"userId": "123", "orderId": "456", "itemId": "789"},
+			Error:  "invalid endpoint format: unused path parameter \"itemId\"",
+		},
+		{
+			Name:   "Mixed placeholders",
+			Format: "https://localhost:8080/users/{userId}/orders/{}",
+			Named:  map[string]string{"userId": "123"},
+			Params: []string{"456"},
+			Error:  "invalid endpoint format: mixed named and positional placeholders",
+		},
+		{
+			Name:   "Positional parameters for named placeholders",
+			Format: "https://localhost:8080/users/{userId}",
+			Named:  map[string]string{"userId": "123"},
+			Params: []string{"456"},
+			Error:  "invalid endpoint format: positional path parameters for named placeholders",
+		},
+		{
+			Name:   "Invalid placeholder name",
+			Format: "https://localhost:8080/users/{user-id}",
+			Named:  map[string]string{"user-id": "123"},
+			Error:  "invalid endpoint format",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			rb := NewRequest(http.MethodGet, test.Format)
+			rb.WithPathParameters(test.Params...)
+			for name, val := range test.Named {
+				rb.WithPathParameter(name, val)
+			}
+
+			req, err := rb.Build(context.Background())
+
+			if test.Error != "" {
+				assert.Nil(t, req)
+				assert.True(t, errors.Is(err, ErrInvalidFormat))
+				assert.EqualError(t, err, test.Error)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.Path, req.URL.Path)
+			}
+		})
+	}
+}
+
+// TestRequestWithAcceptedStatus tests adding accepted status codes to a
+// request builder.
+func TestRequestWithAcceptedStatus(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Builder *RequestBuilder
+		Added   []StatusRange
+		After   []StatusRange
+	}{
+		{
+			Name:    "Adding ranges to empty set",
+			Builder: &RequestBuilder{},
+			Added:   []StatusRange{{From: 200, To: 299}},
+			After:   []StatusRange{{From: 200, To: 299}},
+		},
+		{
+			Name: "Adding ranges to populated set",
+			Builder: &RequestBuilder{
+				accepted: []StatusRange{{From: 200, To: 299}},
+			},
+			Added: []StatusRange{{From: 404, To: 404}},
+			After: []StatusRange{{From: 200, To: 299}, {From: 404, To: 404}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+
+			test.Builder.WithAcceptedStatus(test.Added...)
+
+			assert.Equal(t, test.After, test.Builder.accepted)
+		})
+	}
+}
+
 // TestRequestBuild tests building a request.
 func TestRequestBuild(t *testing.T) {
+	type key struct{}
+	ctx, cancel := context.WithTimeout(
+		context.WithValue(context.Background(), key{}, "value"), time.Minute,
+	)
+	defer cancel()
+
 	tests := []struct {
 		Name          string
 		Builder       *RequestBuilder
@@ -325,7 +559,7 @@ func TestRequestBuild(t *testing.T) {
 				},
 				pathPrms: nil,
 			},
-			Context:       context.Background(),
+			Context:       ctx,
 			Error:         nil,
 			Method:        http.MethodGet,
 			Body:          []byte(""),
@@ -354,7 +588,7 @@ func TestRequestBuild(t *testing.T) {
 				queryPrms: nil,
 				pathPrms:  []string{"4481e035-1711-419f-82bc-bfb72da06375", "01JW3ZFVR44BWEAJRW7TEQ3PK0"},
 			},
-			Context:       context.Background(),
+			Context:       ctx,
 			Error:         nil,
 			Method:        http.MethodPatch,
 			Body:          []byte(`{"Name":"My Phone"}`),
@@ -382,7 +616,7 @@ func TestRequestBuild(t *testing.T) {
 				},
 				pathPrms: nil,
 			},
-			Context:       context.Background(),
+			Context:       ctx,
 			Error:         nil,
 			Method:        http.MethodGet,
 			Body:          []byte(""),
@@ -408,7 +642,7 @@ func TestRequestBuild(t *testing.T) {
 				queryPrms: nil,
 				pathPrms:  nil,
 			},
-			Context:       context.Background(),
+			Context:       ctx,
 			Error:         nil,
 			Method:        http.MethodPost,
 			Body:          []byte("UserUpdated"),
@@ -429,7 +663,7 @@ func TestRequestBuild(t *testing.T) {
 				queryPrms: nil,
 				pathPrms:  nil,
 			},
-			Context: context.Background(),
+			Context: ctx,
 			Error:   ErrInvalidBodyType,
 		},
 		{
@@ -443,7 +677,7 @@ func TestRequestBuild(t *testing.T) {
 				queryPrms: nil,
 				pathPrms:  nil,
 			},
-			Context: context.Background(),
+			Context: ctx,
 			Error:   ErrInvalidBodyType,
 		},
 		{
@@ -457,7 +691,7 @@ func TestRequestBuild(t *testing.T) {
 				queryPrms: nil,
 				pathPrms:  []string{"123", "abc"},
 			},
-			Context: context.Background(),
+			Context: ctx,
 			Error:   ErrInvalidFormat,
 		},
 		{
@@ -471,7 +705,7 @@ func TestRequestBuild(t *testing.T) {
 				queryPrms: nil,
 				pathPrms:  []string{"123", "abc"},
 			},
-			Context: context.Background(),
+			Context: ctx,
 			Error:   ErrInvalidFormat,
 		},
 		{
@@ -485,7 +719,7 @@ func TestRequestBuild(t *testing.T) {
 				queryPrms: nil,
 				pathPrms:  []string{"123", "abc"},
 			},
-			Context: context.Background(),
+			Context: ctx,
 			Error:   ErrInvalidFormat,
 		},
 		{
@@ -499,7 +733,7 @@ func TestRequestBuild(t *testing.T) {
 				queryPrms: nil,
 				pathPrms:  []string{"123", "abc", "xyz"},
 			},
-			Context: context.Background(),
+			Context: ctx,
 			Error:   ErrInvalidFormat,
 		},
 		{
@@ -513,7 +747,7 @@ func TestRequestBuild(t *testing.T) {
 				queryPrms: nil,
 				pathPrms:  []string{"123"},
 			},
-			Context: context.Background(),
+			Context: ctx,
 			Error:   ErrInvalidFormat,
 		},
 		{
@@ -527,7 +761,7 @@ func TestRequestBuild(t *testing.T) {
 				queryPrms: nil,
 				pathPrms:  nil,
 			},
-			Context: context.Background(),
+			Context: ctx,
 			Error: &url.Error{
 				Op:  "parse",
 				URL: "\x00",
@@ -545,7 +779,12 @@ func TestRequestBuild(t *testing.T) {
 			if err == nil {
 				assert.NotNil(t, req)
 
-				assert.Equal(t, test.Context, req.Context())
+				assert.Equal(t, "value", req.Context().Value(key{}))
+				deadline, _ := test.Context.Deadline()
+				reqDeadline, ok := req.Context().Deadline()
+				assert.True(t, ok)
+				assert.Equal(t, deadline, reqDeadline)
+				assert.Equal(t, test.Builder.format, RequestFormat(req))
 				assert.Equal(t, test.Method, req.Method)
 				assert.Equal(t, test.ContentLength, req.ContentLength)
 
@@ -563,3 +802,543 @@ func TestRequestBuild(t *testing.T) {
 		})
 	}
 }
+
+// TestRequestWithUnmarshaler tests setting the unmarshaler of a request builder.
+func TestRequestWithUnmarshaler(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Builder     *RequestBuilder
+		Unmarshaler Unmarshaler
+	}{
+		{
+			Name:        "Setting unmarshaler",
+			Builder:     &RequestBuilder{},
+			Unmarshaler: JsonUnmarshaler,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req := test.Builder.WithUnmarshaler(test.Unmarshaler)
+
+			assert.NotNil(t, req.unmarshaler)
+		})
+	}
+}
+
+// TestRequestSend tests sending a request with the client of the builder.
+func TestRequestSend(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Requester *mockRequester
+		Bound     bool
+		Format    string
+		Error     error
+	}{
+		{
+			Name:      "Successful request",
+			Requester: &mockRequester{StatusCode: 200},
+			Bound:     true,
+			Format:    "http://localhost:8080/users/{}",
+			Error:     nil,
+		},
+		{
+			Name:      "Request builder without client",
+			Requester: &mockRequester{StatusCode: 200},
+			Bound:     false,
+			Format:    "http://localhost:8080/users/{}",
+			Error:     ErrNoClient,
+		},
+		{
+			Name:      "Request fails to build",
+			Requester: &mockRequester{StatusCode: 200},
+			Bound:     true,
+			Format:    "http://localhost:8080/users/{",
+			Error:     ErrInvalidFormat,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			rb := NewRequest(http.MethodGet, test.Format)
+			if test.Bound {
+				rb = NewClient(test.Requester).NewRequest(http.MethodGet, test.Format)
+			}
+
+			res, err := rb.WithPathParameters("123").Send(context.Background())
+
+			assert.Equal(t, test.Error, err)
+			if err == nil {
+				assert.Equal(t, 200, res.StatusCode)
+				assert.Equal(t, 1, test.Requester.CountCalled)
+				assert.Equal(t, "/users/123", test.Requester.LastRequest.URL.Path)
+			} else {
+				assert.Nil(t, res)
+				assert.Equal(t, 0, test.Requester.CountCalled)
+			}
+		})
+	}
+}
+
+// TestRequestSendAndDecode tests sending a request and decoding the response.
+func TestRequestSendAndDecode(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Requester   *mockRequester
+		Unmarshaler Unmarshaler
+		Value       map[string]any
+		Accept      string
+		Error       bool
+	}{
+		{
+			Name: "Decode by content type",
+			Requester: &mockRequester{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       []byte(`{"name":"John Smith"}`),
+			},
+			Value:  map[string]any{"name": "John Smith"},
+			Accept: "application/json, application/xml;q=0.9, text/xml;q=0.8, application/x-www-form-urlencoded;q=0.5",
+		},
+		{
+			Name: "Decode with unmarshaler",
+			Requester: &mockRequester{
+				StatusCode: 200,
+				Body:       []byte(`{"name":"John Smith"}`),
+			},
+			Unmarshaler: JsonUnmarshaler,
+			Value:       map[string]any{"name": "John Smith"},
+		},
+		{
+			Name: "Unexpected status code",
+			Requester: &mockRequester{
+				StatusCode: 404,
+				Body:       []byte(`{"error":"not found"}`),
+			},
+			Unmarshaler: JsonUnmarshaler,
+			Error:       true,
+		},
+		{
+			Name: "Request failed",
+			Requester: &mockRequester{
+				RequestErr: errors.New("failed"),
+			},
+			Accept: "application/json, application/xml;q=0.9, text/xml;q=0.8, application/x-www-form-urlencoded;q=0.5",
+			Error:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cl := NewClient(test.Requester)
+
+			var val map[string]any
+			err := cl.NewRequest(
+				http.MethodGet, "http://localhost:8080/users",
+			).WithUnmarshaler(
+				test.Unmarshaler,
+			).SendAndDecode(context.Background(), &val)
+
+			assert.Equal(t, test.Accept, test.Requester.LastRequest.Header.Get("Accept"))
+			if test.Error {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.Value, val)
+			}
+		})
+	}
+}
+
+// TestRequestBuildNegotiate tests picking the marshaler of a body from the
+// registry of the client.
+func TestRequestBuildNegotiate(t *testing.T) {
+	tests := []struct {
+		Name        string
+		Options     []ClientOption
+		ContentType string
+		Body        []byte
+		Headers     http.Header
+		Error       error
+	}{
+		{
+			Name:        "Marshaler from Content-Type header",
+			ContentType: "application/xml",
+			Body:        []byte("<user><id>1</id><name>John Smith</name></user>"),
+			Headers:     http.Header{"Content-Type": {"application/xml"}},
+		},
+		{
+			Name:        "Content-Type header with parameters",
+			ContentType: "application/json; charset=utf-8",
+			Body:        []byte(`{"id":1,"name":"John Smith"}`),
+			Headers:     http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+		},
+		{
+			Name:    "Marshaler from default content type",
+			Options: []ClientOption{WithDefaultContentType("application/json")},
+			Body:    []byte(`{"id":1,"name":"John Smith"}`),
+			Headers: http.Header{"Content-Type": {"application/json"}},
+		},
+		{
+			Name: "Marshaler from custom registry",
+			Options: []ClientOption{
+				WithRegistry(func() *Registry {
+					reg := NewRegistry()
+					reg.Register("application/vnd.user", Codec{
+						Marshaler: func(body any) ([]byte, map[string][]string, error) {
+							return []byte("user"), nil, nil
+						},
+					})
+					return reg
+				}()),
+				WithDefaultContentType("application/vnd.user"),
+			},
+			Body:    []byte("user"),
+			Headers: http.Header{},
+		},
+		{
+			Name:  "No marshaler",
+			Error: ErrInvalidBodyType,
+		},
+		{
+			Name:        "Unknown content type",
+			ContentType: "application/octet-stream",
+			Error:       ErrInvalidBodyType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cl := NewClient(&mockRequester{}, test.Options...)
+
+			rb := cl.NewRequest(http.MethodPost, "http://localhost:8080/users")
+			rb.WithBody(user{Id: 1, Name: "John Smith"}, nil)
+			if test.ContentType != "" {
+				rb.WithHeader("Content-Type", test.ContentType)
+			}
+
+			req, err := rb.Build(context.Background())
+			assert.Equal(t, test.Error, err)
+			if err != nil {
+				return
+			}
+
+			body, _ := io.ReadAll(req.Body)
+			assert.Equal(t, test.Body, body)
+			assert.Equal(t, test.Headers, req.Header)
+		})
+	}
+}
+
+// TestRequestRoute tests the route of requests built from formats.
+func TestRequestRoute(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Format string
+		Params []string
+		Route  string
+	}{
+		{
+			Name:   "Absolute format",
+			Format: "https://localhost:8080/users/{}/devices?fields=id",
+			Params: []string{"1"},
+			Route:  "/users/{}/devices",
+		},
+		{
+			Name:   "Format without path",
+			Format: "https://localhost:8080",
+			Route:  "/",
+		},
+		{
+			Name:   "Relative format",
+			Format: "/users/{}",
+			Params: []string{"1"},
+			Route:  "/users/{}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, _ := NewRequest(
+				http.MethodGet, test.Format,
+			).WithPathParameters(
+				test.Params...,
+			).Build(context.Background())
+			assert.Equal(t, test.Route, RequestRoute(req))
+		})
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://localhost:8080/users/1", nil)
+	assert.Equal(t, "", RequestRoute(req))
+}
+
+// TestRequestWithMiddleware tests running request scoped middlewares after
+// the middlewares of the client.
+func TestRequestWithMiddleware(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Send   bool
+		Called []string
+	}{
+		{
+			Name:   "Sent by request builder",
+			Send:   true,
+			Called: []string{"client", "request 1", "request 2"},
+		},
+		{
+			Name:   "Sent by client",
+			Send:   false,
+			Called: []string{"client", "request 1", "request 2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			called := []string{}
+			mark := func(name string) func(*Context) {
+				return func(ctx *Context) {
+					called = append(called, name)
+					ctx.Next()
+				}
+			}
+
+			mock := &mockRequester{StatusCode: 200}
+			cl := NewClient(mock)
+			cl.Use(mark("client"))
+
+			rb := cl.NewRequest(http.MethodGet, "http://localhost:8080").
+				WithMiddleware(mark("request 1"), mark("request 2"))
+
+			var err error
+			if test.Send {
+				_, err = rb.Send(context.Background())
+			} else {
+				req, _ := rb.Build(context.Background())
+				_, err = cl.Do(req)
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Called, called)
+			assert.Equal(t, 1, mock.CountCalled)
+
+			called = []string{}
+			cl.Get("http://localhost:8080")
+			assert.Equal(t, []string{"client"}, called)
+		})
+	}
+}
+
+// TestRequestMetaNotInherited tests that requests created with a context
+// derived from a built request do not inherit its details.
+func TestRequestMetaNotInherited(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Nested func(ctx *Context) *http.Request
+	}{
+		{
+			Name: "Request from middleware context",
+			Nested: func(ctx *Context) *http.Request {
+				req, _ := http.NewRequestWithContext(
+					ctx, http.MethodGet, "http://localhost:8080/health", nil,
+				)
+				return req
+			},
+		},
+		{
+			Name: "Request from derived middleware context",
+			Nested: func(ctx *Context) *http.Request {
+				c, cancel := context.WithCancel(ctx.Request.Context())
+				t.Cleanup(cancel)
+				req, _ := http.NewRequestWithContext(
+					c, http.MethodGet, "http://localhost:8080/health", nil,
+				)
+				return req
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			routes := []string{}
+			calls := 0
+
+			mock := &mockRequester{StatusCodes: []int{200, 404}}
+			cl := NewClient(mock)
+			cl.Use(StatusCheck(StatusCheckOptions{}), func(ctx *Context) {
+				routes = append(routes, RequestRoute(ctx.Request))
+				ctx.Next()
+			})
+
+			var nestedErr error
+			_, err := cl.NewRequest(http.MethodGet, "http://localhost:8080/users/{}").
+				WithPathParameters("123").
+				WithAcceptedStatus(StatusRange{From: 404, To: 404}).
+				WithMiddleware(func(ctx *Context) {
+					if calls++; calls > 1 {
+						t.Fatal("request middleware ran for the nested request")
+					}
+					_, nestedErr = cl.Do(test.Nested(ctx))
+					ctx.Next()
+				}).
+				Send(context.Background())
+
+			assert.Nil(t, err)
+			assert.Nil(t, nestedErr)
+			assert.Equal(t, 1, calls)
+			assert.Equal(t, []string{"/users/{}", ""}, routes)
+			assert.Equal(t, 2, mock.CountCalled)
+		})
+	}
+}
+
+// TestRequestMetaCloned tests that copies of built requests keep the details
+// of the request builder.
+func TestRequestMetaCloned(t *testing.T) {
+	type key struct{}
+
+	tests := []struct {
+		Name  string
+		Clone func(req *http.Request) *http.Request
+	}{
+		{
+			Name: "Built request",
+			Clone: func(req *http.Request) *http.Request {
+				return req
+			},
+		},
+		{
+			Name: "Clone with the same context",
+			Clone: func(req *http.Request) *http.Request {
+				return req.Clone(req.Context())
+			},
+		},
+		{
+			Name: "Clone with a derived context",
+			Clone: func(req *http.Request) *http.Request {
+				return req.Clone(context.WithValue(req.Context(), key{}, "value"))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			formats := []string{}
+			calls := 0
+
+			mock := &mockRequester{StatusCode: 404}
+			cl := NewClient(mock)
+			cl.Use(StatusCheck(StatusCheckOptions{}), func(ctx *Context) {
+				formats = append(formats, RequestFormat(ctx.Request))
+				ctx.Next()
+			})
+
+			req, err := cl.NewRequest(http.MethodGet, "http://localhost:8080/users/{}").
+				WithPathParameters("123").
+				WithAcceptedStatus(StatusRange{From: 404, To: 404}).
+				WithMiddleware(func(ctx *Context) {
+					calls++
+					ctx.Next()
+				}).
+				Build(context.Background())
+			assert.Nil(t, err)
+
+			res, err := cl.Do(test.Clone(req))
+
+			assert.Nil(t, err)
+			assert.Equal(t, 404, res.StatusCode)
+			assert.Equal(t, 1, calls)
+			assert.Equal(t, []string{"http://localhost:8080/users/{}"}, formats)
+		})
+	}
+}
+
+// TestRequestWithClientOptions tests overriding the options of the client
+// for a request.
+func TestRequestWithClientOptions(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Options []ClientOption
+		Auth    []string
+		Extra   []string
+		Query   string
+		Body    string
+	}{
+		{
+			Name:    "No options",
+			Options: nil,
+			Auth:    []string{"Bearer client"},
+			Extra:   nil,
+			Query:   "v=1",
+			Body:    `{"name":"John Smith"}`,
+		},
+		{
+			Name:    "Add default header",
+			Options: []ClientOption{WithDefaultHeader("X-Request", "request")},
+			Auth:    []string{"Bearer client"},
+			Extra:   []string{"request"},
+			Query:   "v=1",
+			Body:    `{"name":"John Smith"}`,
+		},
+		{
+			Name: "Override default header",
+			Options: []ClientOption{
+				WithDefaultHeader("Authorization", "Bearer request"),
+			},
+			Auth:  []string{"Bearer request"},
+			Extra: nil,
+			Query: "v=1",
+			Body:  `{"name":"John Smith"}`,
+		},
+		{
+			Name: "Override default query parameter",
+			Options: []ClientOption{
+				WithDefaultQueryParameter("v", []string{"2"}),
+				WithDefaultQueryParameter("w", []string{"3"}),
+			},
+			Auth:  []string{"Bearer client"},
+			Extra: nil,
+			Query: "v=2&w=3",
+			Body:  `{"name":"John Smith"}`,
+		},
+		{
+			Name:    "Override default content type",
+			Options: []ClientOption{WithDefaultContentType("application/xml")},
+			Auth:    []string{"Bearer client"},
+			Extra:   nil,
+			Query:   "v=1",
+			Body:    `<person><name>John Smith</name></person>`,
+		},
+	}
+
+	type person struct {
+		XMLName xml.Name `json:"-" xml:"person"`
+		Name    string   `json:"name" xml:"name"`
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{StatusCode: 200}
+			cl := NewClient(mock,
+				WithDefaultHeader("Authorization", "Bearer client"),
+				WithDefaultQueryParameter("v", []string{"1"}),
+				WithDefaultContentType("application/json"),
+			)
+
+			_, err := cl.NewRequest(http.MethodPost, "http://localhost:8080").
+				WithBody(person{Name: "John Smith"}, nil).
+				WithClientOptions(test.Options...).
+				Send(context.Background())
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Auth, mock.LastRequest.Header.Values("Authorization"))
+			assert.Equal(t, test.Extra, mock.LastRequest.Header.Values("X-Request"))
+			assert.Equal(t, test.Query, mock.LastRequest.URL.RawQuery)
+			assert.Equal(t, test.Body, string(mock.Bodies[0]))
+
+			cl.Get("http://localhost:8080")
+			assert.Equal(t, []string{"Bearer client"}, mock.LastRequest.Header.Values("Authorization"))
+			assert.Empty(t, mock.LastRequest.Header.Values("X-Request"))
+			assert.Equal(t, "v=1", mock.LastRequest.URL.RawQuery)
+		})
+	}
+}
@@ -0,0 +1,422 @@
+package gent
+
+import (
+	"bytes"
+	"encoding/gob"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheOptions configures the Cache middleware.
+type CacheOptions struct {
+	// Store keeps the cached responses. Defaults to a memory store with
+	// the default capacity.
+	Store CacheStore
+
+	// MaxEntrySize is the largest response body in bytes that gets cached.
+	// Defaults to 1 MiB.
+	MaxEntrySize int64
+}
+
+// cacheableStatus are the status codes that can be cached without explicit
+// freshness information.
+var cacheableStatus = map[int]bool{
+	200: true, 203: true, 204: true, 300: true, 301: true, 308: true,
+	404: true, 405: true, 410: true, 414: true, 501: true,
+}
+
+// cacheEntry is a response stored in the cache.
+type cacheEntry struct {
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+	Vary         map[string]string
+	RequestTime  time.Time
+	ResponseTime time.Time
+}
+
+// Cache creates a middleware that caches responses of GET requests as a
+// private cache following HTTP caching semantics (RFC 9111). Fresh responses
+// are returned from the cache without running the rest of the chain, while
+// stale responses are revalidated with conditional requests when they have
+// an ETag or Last-Modified header. Responses are selected by the request
+// headers in their Vary header, and requests with unsafe methods invalidate
+// the cached response of their URL. Requests that are already conditional
+// bypass the cache. Responses to requests with Authorization or Cookie headers
+// are only stored if they are public, so they are not served to callers with
+// other credentials.
+func Cache(opts CacheOptions) func(*Context) {
+	if opts.Store == nil {
+		opts.Store = NewMemoryCacheStore(0)
+	}
+	if opts.MaxEntrySize <= 0 {
+		opts.MaxEntrySize = 1 << 20
+	}
+
+	return func(ctx *Context) {
+		req := ctx.Request
+		if req.Method != http.MethodGet {
+			ctx.Next()
+			invalidateCache(opts.Store, req, ctx.Response)
+			return
+		}
+		if req.Header.Get("If-None-Match") != "" ||
+			req.Header.Get("If-Modified-Since") != "" ||
+			req.Header.Get("Range") != "" {
+			ctx.Next()
+			return
+		}
+
+		key := cacheKey(req.URL)
+		reqCC := parseCacheControl(req.Header)
+		if len(reqCC) == 0 && req.Header.Get("Pragma") == "no-cache" {
+			reqCC["no-cache"] = ""
+		}
+
+		entry := loadCacheEntry(opts.Store, key, req)
+		if entry != nil && entry.usable(reqCC, time.Now()) {
+			ctx.AbortWithResponse(entry.response(req, time.Now()))
+			return
+		}
+		if _, ok := reqCC["only-if-cached"]; ok {
+			ctx.AbortWithResponse(&http.Response{
+				Status:     "504 " + http.StatusText(http.StatusGatewayTimeout),
+				StatusCode: http.StatusGatewayTimeout,
+				Proto:      "HTTP/1.1",
+				ProtoMajor: 1,
+				ProtoMinor: 1,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+				Request:    req,
+			})
+			return
+		}
+
+		revalidating := false
+		if entry != nil {
+			if creq := entry.conditional(req); creq != nil {
+				ctx.Request = creq
+				revalidating = true
+			}
+		}
+
+		reqTime := time.Now()
+		ctx.Next()
+		resTime := time.Now()
+		ctx.Request = req
+
+		res := ctx.Response
+		if res == nil {
+			return
+		}
+
+		if revalidating && res.StatusCode == http.StatusNotModified {
+			if res.Body != nil {
+				res.Body.Close()
+			}
+			entry.update(res.Header, reqTime, resTime)
+			if val, err := entry.encode(); err == nil {
+				opts.Store.Set(key, val)
+			}
+			ctx.Response = entry.response(req, time.Now())
+			return
+		}
+
+		if storable(req, reqCC, res) {
+			storeCacheEntry(opts.Store, key, req, res, reqTime, resTime, opts.MaxEntrySize)
+		}
+	}
+}
+
+// cacheKey returns the key of the cached response of a URL.
+func cacheKey(u *url.URL) string {
+	return http.MethodGet + " " + u.String()
+}
+
+// invalidateCache removes the cached responses of the URL and the Location
+// header of requests with unsafe methods that did not fail.
+func invalidateCache(store CacheStore, req *http.Request, res *http.Response) {
+	switch req.Method {
+	case http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return
+	}
+	if res == nil || res.StatusCode >= 400 {
+		return
+	}
+
+	store.Delete(cacheKey(req.URL))
+	if loc, err := res.Location(); err == nil && loc.Host == req.URL.Host {
+		store.Delete(cacheKey(loc))
+	}
+}
+
+// parseCacheControl returns the directives of the Cache-Control headers with
+// lowercase names and unquoted values.
+func parseCacheControl(hdr http.Header) map[string]string {
+	cc := map[string]string{}
+	for _, val := range hdr.Values("Cache-Control") {
+		for _, dir := range strings.Split(val, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(dir), "=")
+			if name == "" {
+				continue
+			}
+			cc[strings.ToLower(name)] = strings.Trim(arg, `"`)
+		}
+	}
+	return cc
+}
+
+// seconds returns the duration of a directive with a delta-seconds value.
+func seconds(cc map[string]string, name string) (time.Duration, bool) {
+	val, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || n < 0 {
+		return 0, true
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// storable checks if a response can be stored in a private cache. Responses
+// to requests with credentials are only stored if they are public, since the
+// cache can be shared by callers with different credentials.
+func storable(req *http.Request, reqCC map[string]string, res *http.Response) bool {
+	if _, ok := reqCC["no-store"]; ok {
+		return false
+	}
+
+	resCC := parseCacheControl(res.Header)
+	if _, ok := resCC["no-store"]; ok {
+		return false
+	}
+	if req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != "" {
+		if _, ok := resCC["public"]; !ok {
+			return false
+		}
+	}
+	if strings.Contains(res.Header.Get("Vary"), "*") {
+		return false
+	}
+
+	if _, ok := resCC["max-age"]; ok {
+		return true
+	}
+	if _, ok := resCC["public"]; ok {
+		return true
+	}
+	if res.Header.Get("Expires") != "" {
+		return true
+	}
+	return cacheableStatus[res.StatusCode]
+}
+
+// storeCacheEntry reads the body of a response and stores it in the cache.
+// The body of the response is replaced with the content that was read. Bodies
+// larger than the limit are not stored.
+func storeCacheEntry(
+	store CacheStore,
+	key string,
+	req *http.Request,
+	res *http.Response,
+	reqTime time.Time,
+	resTime time.Time,
+	limit int64,
+) {
+	if res.ContentLength > limit {
+		return
+	}
+
+	var body []byte
+	if res.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(res.Body, limit+1))
+		if err != nil || int64(len(body)) > limit {
+			res.Body = &readCloser{
+				Reader: io.MultiReader(bytes.NewReader(body), res.Body),
+				Closer: res.Body,
+			}
+			return
+		}
+		res.Body.Close()
+		res.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	entry := &cacheEntry{
+		StatusCode:   res.StatusCode,
+		Header:       res.Header.Clone(),
+		Body:         body,
+		Vary:         map[string]string{},
+		RequestTime:  reqTime,
+		ResponseTime: resTime,
+	}
+	for _, hdr := range res.Header.Values("Vary") {
+		for _, name := range strings.Split(hdr, ",") {
+			if name = http.CanonicalHeaderKey(strings.TrimSpace(name)); name != "" {
+				entry.Vary[name] = strings.Join(req.Header.Values(name), ", ")
+			}
+		}
+	}
+
+	if val, err := entry.encode(); err == nil {
+		store.Set(key, val)
+	}
+}
+
+// loadCacheEntry returns the cached response of a key if it matches the
+// request headers selected by its Vary header.
+func loadCacheEntry(store CacheStore, key string, req *http.Request) *cacheEntry {
+	val, ok := store.Get(key)
+	if !ok {
+		return nil
+	}
+
+	entry := &cacheEntry{}
+	if err := gob.NewDecoder(bytes.NewReader(val)).Decode(entry); err != nil {
+		return nil
+	}
+	for name, val := range entry.Vary {
+		if strings.Join(req.Header.Values(name), ", ") != val {
+			return nil
+		}
+	}
+	return entry
+}
+
+// encode encodes the entry for a cache store.
+func (e *cacheEntry) encode() ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(e)
+	return buf.Bytes(), err
+}
+
+// date returns the time the response was generated at by the server.
+func (e *cacheEntry) date() time.Time {
+	if t, err := http.ParseTime(e.Header.Get("Date")); err == nil {
+		return t
+	}
+	return e.ResponseTime
+}
+
+// freshness returns the freshness lifetime of the response from its max-age
+// directive, Expires header or a heuristic based on Last-Modified.
+func (e *cacheEntry) freshness() time.Duration {
+	if d, ok := seconds(parseCacheControl(e.Header), "max-age"); ok {
+		return d
+	}
+
+	if exp := e.Header.Get("Expires"); exp != "" {
+		t, err := http.ParseTime(exp)
+		if err != nil {
+			return 0
+		}
+		return t.Sub(e.date())
+	}
+
+	lm, err := http.ParseTime(e.Header.Get("Last-Modified"))
+	if err == nil && cacheableStatus[e.StatusCode] {
+		return e.date().Sub(lm) / 10
+	}
+	return 0
+}
+
+// age returns the current age of the response.
+func (e *cacheEntry) age(now time.Time) time.Duration {
+	apparent := max(0, e.ResponseTime.Sub(e.date()))
+
+	ageValue := time.Duration(0)
+	if n, err := strconv.ParseInt(e.Header.Get("Age"), 10, 64); err == nil {
+		ageValue = time.Duration(n) * time.Second
+	}
+	corrected := ageValue + e.ResponseTime.Sub(e.RequestTime)
+
+	return max(apparent, corrected) + now.Sub(e.ResponseTime)
+}
+
+// usable checks if the response can be returned without revalidation by
+// its freshness and the request's directives.
+func (e *cacheEntry) usable(reqCC map[string]string, now time.Time) bool {
+	resCC := parseCacheControl(e.Header)
+	if _, ok := reqCC["no-cache"]; ok {
+		return false
+	}
+	if _, ok := resCC["no-cache"]; ok {
+		return false
+	}
+
+	fresh, age := e.freshness(), e.age(now)
+	if d, ok := seconds(reqCC, "max-age"); ok && age > d {
+		return false
+	}
+	if d, ok := seconds(reqCC, "min-fresh"); ok && fresh-age < d {
+		return false
+	}
+	if age < fresh {
+		return true
+	}
+
+	if _, ok := resCC["must-revalidate"]; ok {
+		return false
+	}
+	if val, ok := reqCC["max-stale"]; ok {
+		d, _ := seconds(reqCC, "max-stale")
+		return val == "" || age-fresh <= d
+	}
+	return false
+}
+
+// conditional returns a copy of the request with validators of the response,
+// or nil if the response has no validators.
+func (e *cacheEntry) conditional(req *http.Request) *http.Request {
+	etag, lm := e.Header.Get("ETag"), e.Header.Get("Last-Modified")
+	if etag == "" && lm == "" {
+		return nil
+	}
+
+	creq := req.Clone(req.Context())
+	if etag != "" {
+		creq.Header.Set("If-None-Match", etag)
+	}
+	if lm != "" {
+		creq.Header.Set("If-Modified-Since", lm)
+	}
+	return creq
+}
+
+// update replaces the headers of the response with the headers of a 304 Not
+// Modified response that revalidated it.
+func (e *cacheEntry) update(hdr http.Header, reqTime, resTime time.Time) {
+	for key, vals := range hdr {
+		switch key {
+		case "Content-Length", "Content-Encoding", "Transfer-Encoding":
+			continue
+		}
+		e.Header[key] = vals
+	}
+	e.RequestTime = reqTime
+	e.ResponseTime = resTime
+}
+
+// response creates a response of the request from the cached response, with
+// the current age in the Age header.
+func (e *cacheEntry) response(req *http.Request, now time.Time) *http.Response {
+	hdr := e.Header.Clone()
+	hdr.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+
+	return &http.Response{
+		Status:        strconv.Itoa(e.StatusCode) + " " + http.StatusText(e.StatusCode),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        hdr,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
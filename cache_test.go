@@ -0,0 +1,280 @@
+package gent
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cacheRequest is a request sent through the cache middleware in tests.
+type cacheRequest struct {
+	Method string
+	Header http.Header
+}
+
+// TestCache tests serving, revalidating and invalidating cached responses.
+func TestCache(t *testing.T) {
+	past := time.Now().Add(-2 * time.Hour).UTC().Format(http.TimeFormat)
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	now := time.Now().UTC().Format(http.TimeFormat)
+
+	get := cacheRequest{Method: http.MethodGet}
+
+	tests := []struct {
+		Name        string
+		StatusCodes []int
+		Header      http.Header
+		Requests    []cacheRequest
+		Calls       int
+		StatusCode  int
+		Conditional string
+	}{
+		{
+			Name:       "Fresh response",
+			Header:     http.Header{"Cache-Control": {"max-age=60"}},
+			Requests:   []cacheRequest{get, get, get},
+			Calls:      1,
+			StatusCode: 200,
+		},
+		{
+			Name:   "Requests with different tokens",
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+			Requests: []cacheRequest{
+				{Method: http.MethodGet, Header: http.Header{"Authorization": {"Bearer a"}}},
+				{Method: http.MethodGet, Header: http.Header{"Authorization": {"Bearer b"}}},
+			},
+			Calls:      2,
+			StatusCode: 200,
+		},
+		{
+			Name:   "Requests with different cookies",
+			Header: http.Header{"Cache-Control": {"max-age=60"}},
+			Requests: []cacheRequest{
+				{Method: http.MethodGet, Header: http.Header{"Cookie": {"session=a"}}},
+				{Method: http.MethodGet, Header: http.Header{"Cookie": {"session=b"}}},
+			},
+			Calls:      2,
+			StatusCode: 200,
+		},
+		{
+			Name:   "Public response to requests with different tokens",
+			Header: http.Header{"Cache-Control": {"public, max-age=60"}},
+			Requests: []cacheRequest{
+				{Method: http.MethodGet, Header: http.Header{"Authorization": {"Bearer a"}}},
+				{Method: http.MethodGet, Header: http.Header{"Authorization": {"Bearer b"}}},
+			},
+			Calls:      1,
+			StatusCode: 200,
+		},
+		{
+			Name:       "No store response",
+			Header:     http.Header{"Cache-Control": {"no-store, max-age=60"}},
+			Requests:   []cacheRequest{get, get},
+			Calls:      2,
+			StatusCode: 200,
+		},
+		{
+			Name: "No store request",
+			Header: http.Header{
+				"Cache-Control": {"max-age=60"},
+			},
+			Requests: []cacheRequest{
+				{Method: http.MethodGet, Header: http.Header{"Cache-Control": {"no-store"}}},
+				get,
+			},
+			Calls:      2,
+			StatusCode: 200,
+		},
+		{
+			Name:       "No cache request",
+			Header:     http.Header{"Cache-Control": {"max-age=60"}},
+			Requests:   []cacheRequest{get, {Method: http.MethodGet, Header: http.Header{"Pragma": {"no-cache"}}}},
+			Calls:      2,
+			StatusCode: 200,
+		},
+		{
+			Name:       "Request max age exceeded",
+			Header:     http.Header{"Cache-Control": {"max-age=3600"}, "Age": {"120"}},
+			Requests:   []cacheRequest{get, {Method: http.MethodGet, Header: http.Header{"Cache-Control": {"max-age=60"}}}},
+			Calls:      2,
+			StatusCode: 200,
+		},
+		{
+			Name:       "Stale response with max stale",
+			Header:     http.Header{"Cache-Control": {"max-age=60"}, "Date": {past}},
+			Requests:   []cacheRequest{get, {Method: http.MethodGet, Header: http.Header{"Cache-Control": {"max-stale"}}}},
+			Calls:      1,
+			StatusCode: 200,
+		},
+		{
+			Name:       "Stale response without validators",
+			Header:     http.Header{"Cache-Control": {"max-age=60"}, "Date": {past}},
+			Requests:   []cacheRequest{get, get},
+			Calls:      2,
+			StatusCode: 200,
+		},
+		{
+			Name:        "Stale response revalidated",
+			StatusCodes: []int{200, 304},
+			Header:      http.Header{"Cache-Control": {"no-cache"}, "Etag": {`"v1"`}},
+			Requests:    []cacheRequest{get, get},
+			Calls:       2,
+			StatusCode:  200,
+			Conditional: `"v1"`,
+		},
+		{
+			Name:        "Stale response replaced",
+			StatusCodes: []int{200, 201},
+			Header:      http.Header{"Cache-Control": {"max-age=0"}, "Etag": {`"v1"`}},
+			Requests:    []cacheRequest{get, get},
+			Calls:       2,
+			StatusCode:  201,
+			Conditional: `"v1"`,
+		},
+		{
+			Name:       "Expires in the future",
+			Header:     http.Header{"Expires": {future}, "Date": {now}},
+			Requests:   []cacheRequest{get, get},
+			Calls:      1,
+			StatusCode: 200,
+		},
+		{
+			Name:       "Expires in the past",
+			Header:     http.Header{"Expires": {past}, "Date": {now}},
+			Requests:   []cacheRequest{get, get},
+			Calls:      2,
+			StatusCode: 200,
+		},
+		{
+			Name:       "Heuristic freshness",
+			Header:     http.Header{"Last-Modified": {"Mon, 02 Jan 2006 15:04:05 GMT"}},
+			Requests:   []cacheRequest{get, get},
+			Calls:      1,
+			StatusCode: 200,
+		},
+		{
+			Name:        "Status code not cacheable",
+			StatusCodes: []int{500, 500},
+			Header:      http.Header{"Last-Modified": {"Mon, 02 Jan 2006 15:04:05 GMT"}},
+			Requests:    []cacheRequest{get, get},
+			Calls:       2,
+			StatusCode:  500,
+		},
+		{
+			Name:   "Vary header matches",
+			Header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Accept"}},
+			Requests: []cacheRequest{
+				{Method: http.MethodGet, Header: http.Header{"Accept": {"application/json"}}},
+				{Method: http.MethodGet, Header: http.Header{"Accept": {"application/json"}}},
+			},
+			Calls:      1,
+			StatusCode: 200,
+		},
+		{
+			Name:   "Vary header differs",
+			Header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Accept"}},
+			Requests: []cacheRequest{
+				{Method: http.MethodGet, Header: http.Header{"Accept": {"application/json"}}},
+				{Method: http.MethodGet, Header: http.Header{"Accept": {"application/xml"}}},
+			},
+			Calls:      2,
+			StatusCode: 200,
+		},
+		{
+			Name:       "Unsafe method invalidates",
+			Header:     http.Header{"Cache-Control": {"max-age=60"}},
+			Requests:   []cacheRequest{get, {Method: http.MethodPost}, get},
+			Calls:      3,
+			StatusCode: 200,
+		},
+		{
+			Name:       "Only if cached miss",
+			Header:     http.Header{"Cache-Control": {"max-age=60"}},
+			Requests:   []cacheRequest{{Method: http.MethodGet, Header: http.Header{"Cache-Control": {"only-if-cached"}}}},
+			Calls:      0,
+			StatusCode: 504,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{
+				StatusCode:  200,
+				StatusCodes: test.StatusCodes,
+				Header:      test.Header,
+				Body:        []byte(`{"name":"John Smith"}`),
+			}
+			cl := NewClient(mock)
+			cl.Use(Cache(CacheOptions{}))
+
+			var res *http.Response
+			for _, r := range test.Requests {
+				req, _ := http.NewRequest(r.Method, "http://localhost:8080/users", nil)
+				for key, vals := range r.Header {
+					req.Header[key] = vals
+				}
+
+				var err error
+				res, err = cl.Do(req)
+				assert.Nil(t, err)
+
+				body, _ := io.ReadAll(res.Body)
+				res.Body.Close()
+				if res.StatusCode != 504 {
+					assert.Equal(t, []byte(`{"name":"John Smith"}`), body)
+				}
+			}
+
+			assert.Equal(t, test.Calls, mock.CountCalled)
+			assert.Equal(t, test.StatusCode, res.StatusCode)
+			if test.Conditional != "" {
+				assert.Equal(t, test.Conditional, mock.LastRequest.Header.Get("If-None-Match"))
+			}
+		})
+	}
+}
+
+// TestCacheAge tests the Age header of cached responses.
+func TestCacheAge(t *testing.T) {
+	mock := &mockRequester{
+		StatusCode: 200,
+		Header:     http.Header{"Cache-Control": {"max-age=600"}, "Age": {"100"}},
+	}
+	cl := NewClient(mock)
+	cl.Use(Cache(CacheOptions{}))
+
+	for i := 0; i < 2; i++ {
+		res, err := cl.Get("http://localhost:8080/users")
+		assert.Nil(t, err)
+		res.Body.Close()
+
+		if i == 1 {
+			assert.Equal(t, "100", res.Header.Get("Age"))
+		}
+	}
+	assert.Equal(t, 1, mock.CountCalled)
+}
+
+// TestCacheMaxEntrySize tests that large bodies are returned but not cached.
+func TestCacheMaxEntrySize(t *testing.T) {
+	mock := &mockRequester{
+		StatusCode: 200,
+		Header:     http.Header{"Cache-Control": {"max-age=600"}},
+		Body:       []byte("0123456789"),
+	}
+	cl := NewClient(mock)
+	cl.Use(Cache(CacheOptions{MaxEntrySize: 5}))
+
+	for i := 0; i < 2; i++ {
+		res, err := cl.Get("http://localhost:8080/users")
+		assert.Nil(t, err)
+
+		body, _ := io.ReadAll(res.Body)
+		res.Body.Close()
+		assert.Equal(t, []byte("0123456789"), body)
+	}
+	assert.Equal(t, 2, mock.CountCalled)
+}
@@ -0,0 +1,145 @@
+package gent
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CacheStore stores the encoded entries of the Cache middleware by key.
+// Stores are used concurrently, and entries that can not be read are
+// reported as missing.
+type CacheStore interface {
+	Get(key string) (val []byte, ok bool)
+	Set(key string, val []byte)
+	Delete(key string)
+}
+
+// memoryCacheStore is a CacheStore that keeps entries in memory and evicts
+// the least recently used entry when it is full.
+type memoryCacheStore struct {
+	mtx      sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	lru      *list.List
+}
+
+// memoryCacheItem is an entry of the memory cache store.
+type memoryCacheItem struct {
+	key string
+	val []byte
+}
+
+// NewMemoryCacheStore creates a CacheStore that keeps up to capacity entries
+// in memory, evicting the least recently used entry when it is full. The
+// capacity defaults to 1024 entries.
+func NewMemoryCacheStore(capacity int) CacheStore {
+	if capacity <= 0 {
+		capacity = 1024
+	}
+	return &memoryCacheStore{
+		capacity: capacity,
+		items:    map[string]*list.Element{},
+		lru:      list.New(),
+	}
+}
+
+// Get returns the entry of a key and marks it as recently used.
+func (s *memoryCacheStore) Get(key string) ([]byte, bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	s.lru.MoveToFront(elem)
+	return elem.Value.(*memoryCacheItem).val, true
+}
+
+// Set stores the entry of a key, and evicts the least recently used entry
+// if the store is full.
+func (s *memoryCacheStore) Set(key string, val []byte) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		elem.Value.(*memoryCacheItem).val = val
+		s.lru.MoveToFront(elem)
+		return
+	}
+
+	s.items[key] = s.lru.PushFront(&memoryCacheItem{key: key, val: val})
+	if s.lru.Len() > s.capacity {
+		last := s.lru.Back()
+		s.lru.Remove(last)
+		delete(s.items, last.Value.(*memoryCacheItem).key)
+	}
+}
+
+// Delete removes the entry of a key.
+func (s *memoryCacheStore) Delete(key string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		s.lru.Remove(elem)
+		delete(s.items, key)
+	}
+}
+
+// diskCacheStore is a CacheStore that keeps entries in files of a directory.
+type diskCacheStore struct {
+	dir string
+}
+
+// NewDiskCacheStore creates a CacheStore that keeps entries in files of a
+// directory, which is created if it does not exist. Entries are written
+// atomically, and failed writes are ignored.
+func NewDiskCacheStore(dir string) (CacheStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &diskCacheStore{dir: dir}, nil
+}
+
+// Get returns the entry of a key from its file.
+func (s *diskCacheStore) Get(key string) ([]byte, bool) {
+	val, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+	return val, true
+}
+
+// Set writes the entry of a key to a temporary file, and renames it to the
+// file of the key.
+func (s *diskCacheStore) Set(key string, val []byte) {
+	f, err := os.CreateTemp(s.dir, "tmp-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write(val)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		os.Rename(f.Name(), s.path(key))
+	}
+}
+
+// Delete removes the file of a key.
+func (s *diskCacheStore) Delete(key string) {
+	os.Remove(s.path(key))
+}
+
+// path returns the name of the file of a key.
+func (s *diskCacheStore) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,61 @@
+package gent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCacheStores tests setting, getting and deleting entries in the stores.
+func TestCacheStores(t *testing.T) {
+	disk, err := NewDiskCacheStore(t.TempDir())
+	assert.Nil(t, err)
+
+	tests := []struct {
+		Name  string
+		Store CacheStore
+	}{
+		{
+			Name:  "Memory store",
+			Store: NewMemoryCacheStore(0),
+		},
+		{
+			Name:  "Disk store",
+			Store: disk,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, ok := test.Store.Get("GET http://localhost:8080/users")
+			assert.False(t, ok)
+
+			test.Store.Set("GET http://localhost:8080/users", []byte("v1"))
+			test.Store.Set("GET http://localhost:8080/users", []byte("v2"))
+			val, ok := test.Store.Get("GET http://localhost:8080/users")
+			assert.True(t, ok)
+			assert.Equal(t, []byte("v2"), val)
+
+			test.Store.Delete("GET http://localhost:8080/users")
+			_, ok = test.Store.Get("GET http://localhost:8080/users")
+			assert.False(t, ok)
+		})
+	}
+}
+
+// TestMemoryCacheStoreEviction tests evicting the least recently used entry.
+func TestMemoryCacheStoreEviction(t *testing.T) {
+	store := NewMemoryCacheStore(2)
+
+	store.Set("a", []byte("a"))
+	store.Set("b", []byte("b"))
+	store.Get("a")
+	store.Set("c", []byte("c"))
+
+	_, ok := store.Get("a")
+	assert.True(t, ok)
+	_, ok = store.Get("b")
+	assert.False(t, ok)
+	_, ok = store.Get("c")
+	assert.True(t, ok)
+}
@@ -0,0 +1,37 @@
+// Package cborgent provides gent marshalers for CBOR bodies.
+package cborgent
+
+import (
+	"github.com/Soreing/gent"
+	"github.com/fxamacker/cbor/v2"
+)
+
+// ContentType is the media type of CBOR bodies.
+const ContentType = "application/cbor"
+
+// Codec is the marshaler and unmarshaler of the package, which can be
+// registered in a gent.Registry with Register under ContentType.
+var Codec = gent.Codec{
+	Marshaler:   Marshaler,
+	Unmarshaler: Unmarshaler,
+}
+
+// Marshaler uses the cbor marshaler to return the CBOR encoded body, with
+// Content-Type and Accept application/cbor headers.
+func Marshaler(body any) (dat []byte, hdrs map[string][]string, err error) {
+	if dat, err = cbor.Marshal(body); err != nil {
+		return nil, nil, err
+	}
+
+	hdrs = map[string][]string{
+		"Content-Type": {ContentType},
+		"Accept":       {ContentType},
+	}
+	return dat, hdrs, nil
+}
+
+// Unmarshaler uses the cbor unmarshaler to decode the CBOR encoded body into
+// the object.
+func Unmarshaler(data []byte, v any) error {
+	return cbor.Unmarshal(data, v)
+}
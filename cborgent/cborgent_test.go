@@ -0,0 +1,86 @@
+package cborgent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Id   int    `cbor:"id"`
+	Name string `cbor:"name"`
+}
+
+// TestMarshalers tests marshaling and unmarshaling objects with their
+// CBOR encoding.
+func TestMarshalers(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Object  user
+		Bytes   []byte
+		Headers map[string][]string
+	}{
+		{
+			Name:   "Marshal object",
+			Object: user{Id: 123, Name: "John Smith"},
+			Bytes: []byte{
+				// map of 2 pairs
+				0xa2,
+				// text of 2 bytes
+				0x62, 'i', 'd',
+				// uint8 123
+				0x18, 0x7b,
+				// text of 4 bytes
+				0x64, 'n', 'a', 'm', 'e',
+				// text of 10 bytes
+				0x6a, 'J', 'o', 'h', 'n', ' ', 'S', 'm', 'i', 't', 'h',
+			},
+			Headers: map[string][]string{
+				"Content-Type": {"application/cbor"},
+				"Accept":       {"application/cbor"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			dat, hdrs, err := Marshaler(test.Object)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Bytes, dat)
+			assert.Equal(t, test.Headers, hdrs)
+
+			var obj user
+			err = Unmarshaler(dat, &obj)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Object, obj)
+		})
+	}
+}
+
+// TestUnmarshalerInvalid tests unmarshaling invalid bodies.
+func TestUnmarshalerInvalid(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Bytes []byte
+	}{
+		{
+			Name:  "Truncated body",
+			Bytes: []byte{0x82},
+		},
+		{
+			Name:  "Reserved additional information",
+			Bytes: []byte{0x1c},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var obj user
+			err := Unmarshaler(test.Bytes, &obj)
+
+			assert.NotNil(t, err)
+		})
+	}
+}
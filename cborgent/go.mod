@@ -0,0 +1,16 @@
+module github.com/Soreing/gent/cborgent
+
+go 1.24
+
+require (
+	github.com/Soreing/gent v1.1.0
+	github.com/fxamacker/cbor/v2 v2.9.0
+	github.com/stretchr/testify v1.10.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
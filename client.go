@@ -2,8 +2,10 @@ package gent
 
 import (
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 )
 
@@ -15,18 +17,80 @@ type Requester interface {
 
 // Client wraps an http Client with additional features.
 type Client struct {
-	cl   Requester
-	mdws []func(*Context)
+	cl      Requester
+	mdws    []func(*Context)
+	baseUrl *url.URL
+	headers http.Header
+	query   url.Values
+
+	registry    *Registry
+	contentType string
+}
+
+// ClientOption configures optional features of a Client.
+type ClientOption func(*Client)
+
+// WithBaseUrl sets a base URL that relative request URLs are resolved against
+// by the rules of RFC 3986, the same way as url.URL.ResolveReference. For a
+// base URL of "https://localhost:8080/api/", the request URL "users" becomes
+// "https://localhost:8080/api/users", and "/users" becomes
+// "https://localhost:8080/users". Absolute request URLs are not changed.
+func WithBaseUrl(base *url.URL) ClientOption {
+	return func(c *Client) {
+		c.baseUrl = base
+	}
+}
+
+// WithDefaultHeader adds a header that is set on every request performed by
+// the client, unless the request already has the header.
+func WithDefaultHeader(key string, val string) ClientOption {
+	return func(c *Client) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		c.headers.Add(key, val)
+	}
+}
+
+// WithDefaultQueryParameter adds a query parameter that is set on every request
+// performed by the client, unless the request already has the parameter.
+func WithDefaultQueryParameter(key string, vals []string) ClientOption {
+	return func(c *Client) {
+		if c.query == nil {
+			c.query = url.Values{}
+		}
+		c.query[key] = append(c.query[key], vals...)
+	}
+}
+
+// WithRegistry sets the registry of marshalers and unmarshalers used by
+// request builders of the client. Clients use the DefaultRegistry otherwise.
+func WithRegistry(registry *Registry) ClientOption {
+	return func(c *Client) {
+		c.registry = registry
+	}
+}
+
+// WithDefaultContentType sets the media type that request builders of the
+// client encode bodies with when they have no marshaler or Content-Type header.
+func WithDefaultContentType(mediaType string) ClientOption {
+	return func(c *Client) {
+		c.contentType = mediaType
+	}
 }
 
 // NewDefaultClient creates a Client from http.DefaultClient.
-func NewDefaultClient() *Client {
-	return &Client{cl: http.DefaultClient}
+func NewDefaultClient(opts ...ClientOption) *Client {
+	return NewClient(http.DefaultClient, opts...)
 }
 
 // NewClient creates a Client from the provided Requester.
-func NewClient(client Requester) *Client {
-	return &Client{cl: client}
+func NewClient(client Requester, opts ...ClientOption) *Client {
+	c := &Client{cl: client}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // Use adds a middleware style handler function to the execution chain of
@@ -38,23 +102,142 @@ func (c *Client) Use(
 	c.mdws = append(c.mdws, middlewares...)
 }
 
-// Do sends an HTTP request and returns an HTTP response.
+// NewRequest creates a request builder that sends the request with the
+// client through [RequestBuilder.Send] or [RequestBuilder.SendAndDecode].
+func (c *Client) NewRequest(
+	method string,
+	format string,
+) *RequestBuilder {
+	return &RequestBuilder{
+		client: c,
+		method: method,
+		format: format,
+	}
+}
+
+// Do sends an HTTP request and returns an HTTP response. If middlewares add
+// errors to the context, it returns all of them as Errors.
 func (c *Client) Do(
 	req *http.Request,
 ) (res *http.Response, err error) {
-	fns := make([]func(*Context), 0, len(c.mdws)+1)
+	return c.DoWithValues(req)
+}
+
+// DoWithValues sends an HTTP request like Do, and sets values of typed keys
+// in the context of the request before any middleware runs. The values
+// overwrite values of the same keys set by a RequestBuilder.
+//
+// Requests built by a RequestBuilder are sent with the client options and
+// middlewares of the request builder, which are kept by copies of the request
+// such as from Clone. Requests sent by middlewares with a context derived from
+// the context of the request being sent do not inherit them.
+func (c *Client) DoWithValues(
+	req *http.Request,
+	vals ...KeyValue,
+) (res *http.Response, err error) {
+	req, meta := sendRequestMeta(req)
+	c = c.with(meta.options...)
+	req = c.prepare(req)
+
+	fns := make([]func(*Context), 0, len(c.mdws)+len(meta.middlewares)+1)
 	fns = append(fns, c.mdws...)
+	fns = append(fns, meta.middlewares...)
 	fns = append(fns, do)
 
 	ctx := newRequestContext(c.cl, req, fns)
+	ctx.setValues(meta.values)
+	ctx.setValues(vals)
 	ctx.Next()
 
 	if len(ctx.Errors) > 0 {
-		return ctx.Response, ctx.Errors[0]
+		return ctx.Response, ctx.errors()
 	}
 	return ctx.Response, nil
 }
 
+// with returns a copy of the client with options applied, or the client if
+// there are no options. Default headers and query parameters of the options
+// replace the client's values of the same keys, and the client is not changed.
+func (c *Client) with(opts ...ClientOption) *Client {
+	if len(opts) == 0 {
+		return c
+	}
+
+	cp := *c
+	cp.mdws = slices.Clip(c.mdws)
+	cp.headers, cp.query = nil, nil
+	for _, opt := range opts {
+		opt(&cp)
+	}
+
+	if cp.headers == nil {
+		cp.headers = c.headers
+	} else {
+		headers := c.headers.Clone()
+		if headers == nil {
+			headers = http.Header{}
+		}
+		maps.Copy(headers, cp.headers)
+		cp.headers = headers
+	}
+
+	if cp.query == nil {
+		cp.query = c.query
+	} else {
+		query := url.Values{}
+		maps.Copy(query, c.query)
+		maps.Copy(query, cp.query)
+		cp.query = query
+	}
+	return &cp
+}
+
+// codecs returns the registry of the client, or the DefaultRegistry.
+func (c *Client) codecs() *Registry {
+	if c.registry == nil {
+		return DefaultRegistry
+	}
+	return c.registry
+}
+
+// prepare resolves the request's URL against the client's base URL and adds
+// the default headers and query parameters. The request is cloned if it needs
+// to be changed.
+func (c *Client) prepare(
+	req *http.Request,
+) *http.Request {
+	resolve := c.baseUrl != nil && !req.URL.IsAbs() && req.URL.Host == ""
+	if !resolve && c.headers == nil && c.query == nil {
+		return req
+	}
+
+	req = req.Clone(req.Context())
+	if resolve {
+		req.URL = c.baseUrl.ResolveReference(req.URL)
+	}
+
+	for key, vals := range c.headers {
+		if _, ok := req.Header[key]; !ok {
+			req.Header[key] = append([]string(nil), vals...)
+		}
+	}
+
+	if c.query != nil {
+		q, changed := req.URL.Query(), false
+		for key, vals := range c.query {
+			if _, ok := q[key]; !ok {
+				q[key] = append([]string(nil), vals...)
+				changed = true
+			}
+		}
+		if changed {
+			req.URL.RawQuery = q.Encode()
+		}
+	}
+
+	return req
+}
+
 // Get sends a GET HTTP request to the specified URL.
 func (c *Client) Get(
 	url string,
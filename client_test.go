@@ -2,6 +2,7 @@ package gent
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -78,6 +79,33 @@ func TestClientUse(t *testing.T) {
 	}
 }
 
+// TestClientNewRequest tests creating a request builder from a client.
+func TestClientNewRequest(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Method string
+		Format string
+	}{
+		{
+			Name:   "Creating new request",
+			Method: http.MethodGet,
+			Format: "http://localhost:8080/users/{}",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cl := NewClient(&mockRequester{})
+
+			req := cl.NewRequest(test.Method, test.Format)
+
+			assert.Equal(t, cl, req.client)
+			assert.Equal(t, test.Method, req.method)
+			assert.Equal(t, test.Format, req.format)
+		})
+	}
+}
+
 // TestClientDo tests making a request.
 func TestClientDo(t *testing.T) {
 	tests := []struct {
@@ -325,3 +353,120 @@ func TestClientCloseIdleConnections(t *testing.T) {
 		})
 	}
 }
+
+// TestClientOptions tests resolving request URLs against the base URL and
+// adding default headers and query parameters.
+func TestClientOptions(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Options []ClientOption
+		Url     string
+		Header  http.Header
+		Result  string
+		Headers http.Header
+	}{
+		{
+			Name:    "No options",
+			Options: nil,
+			Url:     "https://localhost:8080/users?id=1",
+			Header:  http.Header{},
+			Result:  "https://localhost:8080/users?id=1",
+			Headers: http.Header{},
+		},
+		{
+			Name: "Relative path resolved against base url",
+			Options: []ClientOption{
+				WithBaseUrl(mustParseURL("https://localhost:8080/api/")),
+			},
+			Url:     "users?id=1",
+			Header:  http.Header{},
+			Result:  "https://localhost:8080/api/users?id=1",
+			Headers: http.Header{},
+		},
+		{
+			Name: "Absolute path resolved against base url",
+			Options: []ClientOption{
+				WithBaseUrl(mustParseURL("https://localhost:8080/api/")),
+			},
+			Url:     "/users",
+			Header:  http.Header{},
+			Result:  "https://localhost:8080/users",
+			Headers: http.Header{},
+		},
+		{
+			Name: "Absolute url not resolved against base url",
+			Options: []ClientOption{
+				WithBaseUrl(mustParseURL("https://localhost:8080/api/")),
+			},
+			Url:     "https://example.com/users",
+			Header:  http.Header{},
+			Result:  "https://example.com/users",
+			Headers: http.Header{},
+		},
+		{
+			Name: "Default headers and query parameters",
+			Options: []ClientOption{
+				WithDefaultHeader("Authorization", "Bearer x.y.z"),
+				WithDefaultHeader("X-Api-Key", "cGxhY2Vob2xkZXI="),
+				WithDefaultQueryParameter("api-version", []string{"2"}),
+				WithDefaultQueryParameter("ids", []string{"1", "2"}),
+			},
+			Url:    "https://localhost:8080/users?ids=3",
+			Header: http.Header{"X-Api-Key": {"dGVzdGluZw=="}},
+			Result: "https://localhost:8080/users?api-version=2&ids=3",
+			Headers: http.Header{
+				"Authorization": {"Bearer x.y.z"},
+				"X-Api-Key":     {"dGVzdGluZw=="},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{}
+			cl := NewClient(mock, test.Options...)
+
+			req, _ := http.NewRequest(http.MethodGet, test.Url, nil)
+			req.Header = test.Header
+			_, err := cl.Do(req)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Result, mock.LastRequest.URL.String())
+			assert.Equal(t, test.Headers, mock.LastRequest.Header)
+		})
+	}
+}
+
+// TestClientOptionsBuilder tests sending requests with relative formats
+// through a client with a base url.
+func TestClientOptionsBuilder(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Format string
+		Params []string
+		Result string
+	}{
+		{
+			Name:   "Relative format with placeholders",
+			Format: "users/{}/devices",
+			Params: []string{"Hello, World!"},
+			Result: "https://localhost:8080/api/users/Hello%2C%20World%21/devices",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{}
+			cl := NewClient(mock, WithBaseUrl(mustParseURL("https://localhost:8080/api/")))
+
+			_, err := cl.NewRequest(
+				http.MethodGet, test.Format,
+			).WithPathParameters(
+				test.Params...,
+			).Send(context.Background())
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Result, mock.LastRequest.URL.String())
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package gent
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CoalesceOptions configures the Coalesce middleware.
+type CoalesceOptions struct {
+	// Methods are the request methods that are coalesced. Defaults to GET
+	// and HEAD.
+	Methods []string
+
+	// Headers are the request headers that identify requests in addition to
+	// their method and URL. Defaults to Accept, Accept-Encoding,
+	// Accept-Language, Authorization and Cookie.
+	Headers []string
+
+	// MaxBodySize is the largest response body in bytes that is shared with
+	// the callers that joined a request. Callers that joined a request with a
+	// larger response body send their own requests. Defaults to 1 MiB.
+	MaxBodySize int64
+}
+
+// flight is a request that is in flight for one or more callers.
+type flight struct {
+	done      chan struct{}
+	followers int
+
+	shared bool
+	src    *Context
+	res    *http.Response
+	body   []byte
+}
+
+// Coalesce creates a middleware that deduplicates identical requests that
+// are in flight at the same time. The first caller sends the request, and the
+// callers that join it while it is in flight get a copy of its response with
+// an independent body. The response body is only read into memory when other
+// callers joined the request. Callers stop waiting when their request's
+// context is done, and send their own requests when the response body is
+// larger than the maximum size or the first caller's context was done.
+// Requests with bodies are never coalesced.
+func Coalesce(opts CoalesceOptions) func(*Context) {
+	if len(opts.Methods) == 0 {
+		opts.Methods = []string{http.MethodGet, http.MethodHead}
+	}
+	if opts.Headers == nil {
+		opts.Headers = []string{
+			"Accept", "Accept-Encoding", "Accept-Language",
+			"Authorization", "Cookie",
+		}
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 1 << 20
+	}
+
+	mtx := sync.Mutex{}
+	flights := map[string]*flight{}
+
+	return func(ctx *Context) {
+		req := ctx.Request
+		if !contains(opts.Methods, req.Method) ||
+			(req.Body != nil && req.Body != http.NoBody) {
+			ctx.Next()
+			return
+		}
+
+		key := coalesceKey(req, opts.Headers)
+
+		mtx.Lock()
+		f, ok := flights[key]
+		if ok {
+			f.followers++
+			mtx.Unlock()
+
+			select {
+			case <-f.done:
+				if f.shared {
+					f.deliver(ctx)
+				} else {
+					ctx.Next()
+				}
+			case <-req.Context().Done():
+				mtx.Lock()
+				f.followers--
+				mtx.Unlock()
+				ctx.Error(req.Context().Err())
+			}
+			return
+		}
+
+		f = &flight{done: make(chan struct{})}
+		flights[key] = f
+		mtx.Unlock()
+		defer close(f.done)
+
+		errc := len(ctx.Errors)
+		ctx.Next()
+
+		mtx.Lock()
+		delete(flights, key)
+		followers := f.followers
+		mtx.Unlock()
+
+		if followers > 0 {
+			f.share(ctx, errc, opts.MaxBodySize)
+		}
+	}
+}
+
+// coalesceKey returns the key that identifies identical requests.
+func coalesceKey(req *http.Request, headers []string) string {
+	var sb strings.Builder
+	sb.WriteString(req.Method)
+	sb.WriteByte(' ')
+	sb.WriteString(req.URL.String())
+	for _, hdr := range headers {
+		sb.WriteByte('\n')
+		sb.WriteString(hdr)
+		sb.WriteByte(':')
+		sb.WriteString(strings.Join(req.Header.Values(hdr), ", "))
+	}
+	return sb.String()
+}
+
+// share keeps a copy of the response and the errors of the request that
+// were added after the index errc, so that they can be delivered to the
+// callers that joined the request. The response body is read into memory, and
+// the response is not shared if its body is larger than the limit or the
+// request's context is done.
+func (f *flight) share(ctx *Context, errc int, limit int64) {
+	if ctx.Request.Context().Err() != nil {
+		return
+	}
+
+	if res := ctx.Response; res != nil {
+		if res.Body != nil && res.Body != http.NoBody {
+			if res.ContentLength > limit {
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(res.Body, limit+1))
+			if err != nil || int64(len(body)) > limit {
+				res.Body = &readCloser{
+					Reader: io.MultiReader(bytes.NewReader(body), res.Body),
+					Closer: res.Body,
+				}
+				return
+			}
+			res.Body.Close()
+			res.Body = io.NopCloser(bytes.NewReader(body))
+			f.body = body
+		}
+
+		shared := *res
+		shared.Header = res.Header.Clone()
+		f.res = &shared
+	}
+
+	f.src = &Context{
+		Errors:  slices.Clone(ctx.Errors[errc:]),
+		origins: slices.Clone(ctx.alignedOrigins()[errc:]),
+		aborted: ctx.IsAborted(),
+	}
+	f.shared = true
+}
+
+// deliver sets a copy of the shared response and the errors on a context.
+func (f *flight) deliver(ctx *Context) {
+	ctx.joinErrors(f.src)
+	if f.src.IsAborted() {
+		ctx.Abort()
+	}
+	if f.res == nil {
+		return
+	}
+
+	res := *f.res
+	res.Header = f.res.Header.Clone()
+	res.Body = io.NopCloser(bytes.NewReader(f.body))
+	res.Request = ctx.Request
+	ctx.Response = &res
+}
@@ -0,0 +1,245 @@
+package gent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCoalesce tests deduplicating identical requests that are in flight.
+func TestCoalesce(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Method  string
+		Headers []http.Header
+		Calls   int
+	}{
+		{
+			Name:    "Identical requests",
+			Method:  http.MethodGet,
+			Headers: []http.Header{{}, {}, {}, {}, {}},
+			Calls:   1,
+		},
+		{
+			Name:   "Different key headers",
+			Method: http.MethodGet,
+			Headers: []http.Header{
+				{"Authorization": {"Bearer a"}},
+				{"Authorization": {"Bearer b"}},
+			},
+			Calls: 2,
+		},
+		{
+			Name:   "Different other headers",
+			Method: http.MethodGet,
+			Headers: []http.Header{
+				{"X-Request-Id": {"a"}},
+				{"X-Request-Id": {"b"}},
+			},
+			Calls: 1,
+		},
+		{
+			Name:    "Unsafe method",
+			Method:  http.MethodDelete,
+			Headers: []http.Header{{}, {}},
+			Calls:   2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       []byte(`{"name":"John Smith"}`),
+			}
+			mtx, calls := sync.Mutex{}, 0
+			started := make(chan struct{})
+			release := make(chan struct{})
+
+			cl := NewClient(mock)
+			cl.Use(Coalesce(CoalesceOptions{}))
+			cl.Use(func(ctx *Context) {
+				mtx.Lock()
+				calls++
+				mtx.Unlock()
+				started <- struct{}{}
+				<-release
+
+				mtx.Lock()
+				defer mtx.Unlock()
+				ctx.Next()
+			})
+
+			wg := sync.WaitGroup{}
+			bodies := make([][]byte, len(test.Headers))
+			for i, hdr := range test.Headers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					req, _ := http.NewRequest(test.Method, "http://localhost:8080/users", nil)
+					req.Header = hdr
+					res, err := cl.Do(req)
+					assert.Nil(t, err)
+
+					bodies[i], _ = io.ReadAll(res.Body)
+					res.Body.Close()
+				}()
+			}
+
+			for i := 0; i < test.Calls; i++ {
+				<-started
+			}
+			time.Sleep(20 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			assert.Equal(t, test.Calls, calls)
+			for _, body := range bodies {
+				assert.Equal(t, []byte(`{"name":"John Smith"}`), body)
+			}
+		})
+	}
+}
+
+// TestCoalesceCancel tests that callers stop waiting when their context is
+// cancelled, and that callers send their own requests when the context of the
+// first caller is cancelled.
+func TestCoalesceCancel(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Leader bool
+		Calls  int
+	}{
+		{
+			Name:   "Follower cancelled",
+			Leader: false,
+			Calls:  1,
+		},
+		{
+			Name:   "Leader cancelled",
+			Leader: true,
+			Calls:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			started := make(chan struct{}, 2)
+			release := make(chan struct{})
+
+			mock := &mockRequester{StatusCode: 200, Body: []byte("gent")}
+			cl := NewClient(mock)
+			cl.Use(Coalesce(CoalesceOptions{}))
+			cl.Use(func(ctx *Context) {
+				started <- struct{}{}
+				select {
+				case <-release:
+					ctx.Next()
+				case <-ctx.Done():
+					ctx.Error(ctx.Err())
+				}
+			})
+
+			send := func(c context.Context, out chan<- error, body *[]byte) {
+				req, _ := http.NewRequestWithContext(c, http.MethodGet, "http://localhost:8080/users", nil)
+				res, err := cl.Do(req)
+				if err == nil {
+					*body, _ = io.ReadAll(res.Body)
+					res.Body.Close()
+				}
+				out <- err
+			}
+
+			leaderCtx, cancelLeader := context.WithCancel(context.Background())
+			defer cancelLeader()
+			followerCtx, cancelFollower := context.WithCancel(context.Background())
+			defer cancelFollower()
+
+			var leaderBody, followerBody []byte
+			leaderErr, followerErr := make(chan error), make(chan error)
+			go send(leaderCtx, leaderErr, &leaderBody)
+			<-started
+			go send(followerCtx, followerErr, &followerBody)
+			time.Sleep(20 * time.Millisecond)
+
+			if test.Leader {
+				cancelLeader()
+				assert.ErrorIs(t, <-leaderErr, context.Canceled)
+				<-started
+				close(release)
+				assert.Nil(t, <-followerErr)
+				assert.Equal(t, []byte("gent"), followerBody)
+			} else {
+				cancelFollower()
+				assert.ErrorIs(t, <-followerErr, context.Canceled)
+				close(release)
+				assert.Nil(t, <-leaderErr)
+				assert.Equal(t, []byte("gent"), leaderBody)
+			}
+			assert.Equal(t, test.Calls, mock.CountCalled)
+		})
+	}
+}
+
+// TestCoalesceMaxBodySize tests that responses with bodies larger than the
+// maximum size are not shared.
+func TestCoalesceMaxBodySize(t *testing.T) {
+	tests := []struct {
+		Name        string
+		MaxBodySize int64
+		Calls       int
+	}{
+		{Name: "Body within the limit", MaxBodySize: 10, Calls: 1},
+		{Name: "Body over the limit", MaxBodySize: 5, Calls: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			started := make(chan struct{}, 2)
+			release := make(chan struct{})
+
+			mock := &mockRequester{StatusCode: 200, Body: []byte("0123456789")}
+			cl := NewClient(mock)
+			cl.Use(Coalesce(CoalesceOptions{MaxBodySize: test.MaxBodySize}))
+			cl.Use(func(ctx *Context) {
+				started <- struct{}{}
+				<-release
+				ctx.Next()
+			})
+
+			wg := sync.WaitGroup{}
+			bodies := make([][]byte, 2)
+			for i := range bodies {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					res, err := cl.Get("http://localhost:8080/users")
+					assert.Nil(t, err)
+
+					bodies[i], _ = io.ReadAll(res.Body)
+					res.Body.Close()
+				}()
+				if i == 0 {
+					<-started
+				}
+			}
+
+			time.Sleep(20 * time.Millisecond)
+			close(release)
+			wg.Wait()
+
+			assert.Equal(t, test.Calls, mock.CountCalled)
+			for _, body := range bodies {
+				assert.Equal(t, []byte("0123456789"), body)
+			}
+		})
+	}
+}
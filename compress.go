@@ -0,0 +1,321 @@
+package gent
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrUnsupportedEncoding is returned when a body is compressed with a content
+// encoding that was not registered with RegisterEncoding.
+var ErrUnsupportedEncoding = errors.New("unsupported content encoding")
+
+// Encoding compresses and decompresses bodies of a content encoding.
+type Encoding struct {
+	// Compress returns a writer that compresses content into w. Closing the
+	// writer flushes the compressed content, but does not close w.
+	Compress func(w io.Writer) (io.WriteCloser, error)
+
+	// Decompress returns a reader that decompresses content from r. Closing
+	// the reader does not close r.
+	Decompress func(r io.Reader) (io.ReadCloser, error)
+}
+
+// encodings stores the registered content encodings in the order they were
+// registered, which is the order they appear in Accept-Encoding headers.
+var encodings = struct {
+	mtx   sync.RWMutex
+	encs  map[string]Encoding
+	order []string
+}{
+	encs: map[string]Encoding{
+		"gzip": {
+			Compress: func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(w), nil
+			},
+			Decompress: func(r io.Reader) (io.ReadCloser, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		"deflate": {
+			Compress: func(w io.Writer) (io.WriteCloser, error) {
+				return zlib.NewWriter(w), nil
+			},
+			Decompress: func(r io.Reader) (io.ReadCloser, error) {
+				return zlib.NewReader(r)
+			},
+		},
+	},
+	order: []string{"gzip", "deflate"},
+}
+
+// RegisterEncoding adds a content encoding that can be used to compress
+// request bodies and decompress response bodies. The package registers gzip
+// and deflate. If there was already an encoding registered with the same name,
+// it will overwrite it.
+func RegisterEncoding(name string, enc Encoding) {
+	name = strings.ToLower(name)
+
+	encodings.mtx.Lock()
+	defer encodings.mtx.Unlock()
+
+	if _, ok := encodings.encs[name]; !ok {
+		encodings.order = append(encodings.order, name)
+	}
+	encodings.encs[name] = enc
+}
+
+// getEncoding returns the registered content encoding with a name.
+func getEncoding(name string) (Encoding, bool) {
+	encodings.mtx.RLock()
+	defer encodings.mtx.RUnlock()
+
+	enc, ok := encodings.encs[strings.ToLower(strings.TrimSpace(name))]
+	return enc, ok
+}
+
+// encodingNames returns the names of the registered content encodings.
+func encodingNames() []string {
+	encodings.mtx.RLock()
+	defer encodings.mtx.RUnlock()
+
+	return append([]string(nil), encodings.order...)
+}
+
+// CompressionOptions configures the compression of request bodies.
+type CompressionOptions struct {
+	// Encoding is the name of the registered content encoding that bodies are
+	// compressed with. Defaults to gzip.
+	Encoding string
+
+	// MinSize is the smallest body in bytes that gets compressed. Bodies with
+	// an unknown length are always compressed. Defaults to 1024.
+	MinSize int64
+}
+
+// defaults returns the options with default values for the unset fields.
+func (opts CompressionOptions) defaults() CompressionOptions {
+	if opts.Encoding == "" {
+		opts.Encoding = "gzip"
+	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = 1024
+	}
+	return opts
+}
+
+// Compress creates a middleware that compresses request bodies with a content
+// encoding while they are being sent, and sets the Content-Encoding header.
+// Bodies smaller than the minimum size or that already have a Content-Encoding
+// header are sent as they are. Requests that can be replayed stay replayable.
+func Compress(opts CompressionOptions) func(*Context) {
+	opts = opts.defaults()
+
+	return func(ctx *Context) {
+		if compressible(ctx.Request, opts) {
+			req := ctx.Request.Clone(ctx.Request.Context())
+			if err := compressRequest(req, opts); err != nil {
+				ctx.AbortWithError(err)
+				return
+			}
+			ctx.Request = req
+		}
+		ctx.Next()
+	}
+}
+
+// compressible checks if the body of a request should be compressed.
+func compressible(req *http.Request, opts CompressionOptions) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return false
+	}
+	if req.Header.Get("Content-Encoding") != "" {
+		return false
+	}
+	return req.ContentLength <= 0 || req.ContentLength >= opts.MinSize
+}
+
+// compressRequest replaces the body of a request with a body that compresses
+// the content while it is read.
+func compressRequest(req *http.Request, opts CompressionOptions) error {
+	enc, ok := getEncoding(opts.Encoding)
+	if !ok || enc.Compress == nil {
+		return ErrUnsupportedEncoding
+	}
+
+	req.Body = newCompressedBody(req.Body, enc)
+	if getBody := req.GetBody; getBody != nil {
+		req.GetBody = func() (io.ReadCloser, error) {
+			body, err := getBody()
+			if err != nil {
+				return nil, err
+			}
+			return newCompressedBody(body, enc), nil
+		}
+	}
+
+	req.ContentLength = -1
+	req.Header.Del("Content-Length")
+	req.Header.Set("Content-Encoding", strings.ToLower(opts.Encoding))
+	return nil
+}
+
+// compressedBody is a request body that compresses the content of another
+// body into a pipe.
+type compressedBody struct {
+	*pipeBody
+	src io.Closer
+}
+
+// newCompressedBody creates a body that compresses the content of src.
+func newCompressedBody(src io.ReadCloser, enc Encoding) *compressedBody {
+	return &compressedBody{
+		pipeBody: newPipeBody(func(w io.Writer) error {
+			zw, err := enc.Compress(w)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(zw, src); err != nil {
+				zw.Close()
+				return err
+			}
+			return zw.Close()
+		}),
+		src: src,
+	}
+}
+
+// Close stops compressing the content and closes the source body.
+func (b *compressedBody) Close() error {
+	b.pipeBody.Close()
+	return b.src.Close()
+}
+
+// DecompressionOptions configures the decompression of response bodies.
+type DecompressionOptions struct {
+	// Encodings are the names of the registered content encodings that are
+	// accepted. Defaults to every registered encoding.
+	Encodings []string
+}
+
+// Decompress creates a middleware that sets the Accept-Encoding header of
+// requests that have none, and decompresses response bodies with the content
+// encodings in their Content-Encoding header. Decompressed responses have no
+// Content-Encoding and Content-Length headers, and their Uncompressed field
+// is set. Responses with unsupported encodings are returned as they are.
+func Decompress(opts DecompressionOptions) func(*Context) {
+	return func(ctx *Context) {
+		accepted := opts.Encodings
+		if len(accepted) == 0 {
+			accepted = encodingNames()
+		}
+
+		if ctx.Request.Header.Get("Accept-Encoding") == "" {
+			req := ctx.Request.Clone(ctx.Request.Context())
+			req.Header.Set("Accept-Encoding", strings.Join(accepted, ", "))
+			ctx.Request = req
+		}
+
+		ctx.Next()
+
+		if res := ctx.Response; res != nil && res.Body != nil {
+			decompressResponse(ctx.Request, res, accepted)
+		}
+	}
+}
+
+// decompressResponse replaces the body of a response with a body that
+// decompresses the content while it is read. Encodings are removed in the
+// reverse order they were applied.
+func decompressResponse(
+	req *http.Request,
+	res *http.Response,
+	accepted []string,
+) {
+	if req.Method == http.MethodHead ||
+		res.StatusCode == http.StatusNoContent ||
+		res.StatusCode == http.StatusNotModified {
+		return
+	}
+
+	var names []string
+	for _, hdr := range res.Header.Values("Content-Encoding") {
+		for _, name := range strings.Split(hdr, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name != "" && name != "identity" {
+				names = append(names, name)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	encs := make([]Encoding, len(names))
+	for i, name := range names {
+		enc, ok := getEncoding(name)
+		if !ok || enc.Decompress == nil || !contains(accepted, name) {
+			return
+		}
+		encs[i] = enc
+	}
+
+	body := res.Body
+	for i := len(encs) - 1; i >= 0; i-- {
+		body = &decompressedBody{src: body, decompress: encs[i].Decompress}
+	}
+
+	res.Body = body
+	res.ContentLength = -1
+	res.Uncompressed = true
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+}
+
+// contains checks if a list of encoding names contains a name.
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(strings.TrimSpace(n), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// decompressedBody is a response body that decompresses the content of
+// another body. The decompressor is created on the first read, so empty
+// bodies that are never read do not fail.
+type decompressedBody struct {
+	src        io.ReadCloser
+	decompress func(io.Reader) (io.ReadCloser, error)
+	rc         io.ReadCloser
+	err        error
+}
+
+// Read reads decompressed content, and creates the decompressor on the
+// first call.
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	if b.rc == nil && b.err == nil {
+		if rc, err := b.decompress(b.src); err != nil {
+			b.err = err
+		} else {
+			b.rc = rc
+		}
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.rc.Read(p)
+}
+
+// Close closes the decompressor and the source body.
+func (b *decompressedBody) Close() error {
+	if b.rc != nil {
+		b.rc.Close()
+	}
+	return b.src.Close()
+}
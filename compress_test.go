@@ -0,0 +1,315 @@
+package gent
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gzipBytes compresses data with gzip.
+func gzipBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// deflateBytes compresses data with zlib.
+func deflateBytes(data []byte) []byte {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+// TestCompress tests compressing request bodies in the middleware.
+func TestCompress(t *testing.T) {
+	large := []byte(strings.Repeat("gent", 512))
+
+	tests := []struct {
+		Name       string
+		Options    CompressionOptions
+		Body       io.Reader
+		Headers    http.Header
+		Compressed []byte
+		Encoding   string
+		Error      error
+	}{
+		{
+			Name:       "Compress large body",
+			Options:    CompressionOptions{},
+			Body:       bytes.NewReader(large),
+			Compressed: gzipBytes(large),
+			Encoding:   "gzip",
+		},
+		{
+			Name:       "Compress with deflate",
+			Options:    CompressionOptions{Encoding: "deflate"},
+			Body:       bytes.NewReader(large),
+			Compressed: deflateBytes(large),
+			Encoding:   "deflate",
+		},
+		{
+			Name:       "Compress body with unknown length",
+			Options:    CompressionOptions{MinSize: 1 << 20},
+			Body:       io.MultiReader(bytes.NewReader(large)),
+			Compressed: gzipBytes(large),
+			Encoding:   "gzip",
+		},
+		{
+			Name:       "Skip small body",
+			Options:    CompressionOptions{},
+			Body:       strings.NewReader("gent"),
+			Compressed: []byte("gent"),
+			Encoding:   "",
+		},
+		{
+			Name:       "Skip encoded body",
+			Options:    CompressionOptions{},
+			Body:       bytes.NewReader(large),
+			Headers:    http.Header{"Content-Encoding": {"br"}},
+			Compressed: large,
+			Encoding:   "br",
+		},
+		{
+			Name:    "Unsupported encoding",
+			Options: CompressionOptions{Encoding: "lzma"},
+			Body:    bytes.NewReader(large),
+			Error:   ErrUnsupportedEncoding,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{StatusCode: 200}
+			cl := NewClient(mock)
+			cl.Use(Compress(test.Options))
+
+			req, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/logs", test.Body)
+			for key, vals := range test.Headers {
+				req.Header[key] = vals
+			}
+
+			_, err := cl.Do(req)
+			assert.ErrorIs(t, err, test.Error)
+			if err != nil {
+				assert.Equal(t, 0, mock.CountCalled)
+				return
+			}
+
+			assert.Equal(t, test.Compressed, mock.Bodies[0])
+			assert.Equal(t, test.Encoding, mock.LastRequest.Header.Get("Content-Encoding"))
+			assert.Equal(t, test.Headers.Get("Content-Encoding"), req.Header.Get("Content-Encoding"))
+		})
+	}
+}
+
+// TestCompressReplay tests that compressed bodies can be replayed.
+func TestCompressReplay(t *testing.T) {
+	large := []byte(strings.Repeat("gent", 512))
+
+	req, _ := http.NewRequest(http.MethodPost, "http://localhost:8080/logs", bytes.NewReader(large))
+	err := compressRequest(req, CompressionOptions{}.defaults())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-1), req.ContentLength)
+
+	first, _ := io.ReadAll(req.Body)
+	req.Body.Close()
+
+	body, err := req.GetBody()
+	assert.Nil(t, err)
+	second, _ := io.ReadAll(body)
+
+	assert.Equal(t, gzipBytes(large), first)
+	assert.Equal(t, first, second)
+}
+
+// TestRequestWithCompression tests compressing the body of a request builder.
+func TestRequestWithCompression(t *testing.T) {
+	large := strings.Repeat("gent", 512)
+
+	tests := []struct {
+		Name     string
+		Body     string
+		Options  CompressionOptions
+		Expected []byte
+		Encoding string
+		Error    error
+	}{
+		{
+			Name:     "Compress large body",
+			Body:     large,
+			Expected: gzipBytes([]byte(large)),
+			Encoding: "gzip",
+		},
+		{
+			Name:     "Skip small body",
+			Body:     "gent",
+			Expected: []byte("gent"),
+			Encoding: "",
+		},
+		{
+			Name:     "Custom minimum size",
+			Body:     "gent",
+			Options:  CompressionOptions{Encoding: "deflate", MinSize: 4},
+			Expected: deflateBytes([]byte("gent")),
+			Encoding: "deflate",
+		},
+		{
+			Name:    "Unsupported encoding",
+			Body:    large,
+			Options: CompressionOptions{Encoding: "lzma"},
+			Error:   ErrUnsupportedEncoding,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, err := NewRequest(
+				http.MethodPost, "http://localhost:8080/logs",
+			).WithRawBody(
+				[]byte(test.Body),
+			).WithCompression(
+				test.Options,
+			).Build(context.Background())
+
+			assert.Equal(t, test.Error, err)
+			if err != nil {
+				return
+			}
+
+			body, _ := io.ReadAll(req.Body)
+			assert.Equal(t, test.Expected, body)
+			assert.Equal(t, test.Encoding, req.Header.Get("Content-Encoding"))
+		})
+	}
+}
+
+// TestDecompress tests decompressing response bodies in the middleware.
+func TestDecompress(t *testing.T) {
+	data := []byte(`{"name":"John Smith"}`)
+
+	tests := []struct {
+		Name           string
+		Options        DecompressionOptions
+		Method         string
+		AcceptEncoding string
+		Header         http.Header
+		Body           []byte
+		Expected       []byte
+		ExpectedAccept string
+		Encoding       string
+		Uncompressed   bool
+	}{
+		{
+			Name:           "Decompress gzip",
+			Method:         http.MethodGet,
+			Header:         http.Header{"Content-Encoding": {"gzip"}},
+			Body:           gzipBytes(data),
+			Expected:       data,
+			ExpectedAccept: "gzip, deflate",
+			Uncompressed:   true,
+		},
+		{
+			Name:           "Decompress deflate",
+			Method:         http.MethodGet,
+			Header:         http.Header{"Content-Encoding": {"deflate"}},
+			Body:           deflateBytes(data),
+			Expected:       data,
+			ExpectedAccept: "gzip, deflate",
+			Uncompressed:   true,
+		},
+		{
+			Name:           "Decompress multiple encodings",
+			Method:         http.MethodGet,
+			Header:         http.Header{"Content-Encoding": {"deflate, gzip"}},
+			Body:           gzipBytes(deflateBytes(data)),
+			Expected:       data,
+			ExpectedAccept: "gzip, deflate",
+			Uncompressed:   true,
+		},
+		{
+			Name:           "Keep Accept-Encoding header",
+			Method:         http.MethodGet,
+			AcceptEncoding: "gzip",
+			Header:         http.Header{"Content-Encoding": {"gzip"}},
+			Body:           gzipBytes(data),
+			Expected:       data,
+			ExpectedAccept: "gzip",
+			Uncompressed:   true,
+		},
+		{
+			Name:           "Encoding not accepted",
+			Options:        DecompressionOptions{Encodings: []string{"gzip"}},
+			Method:         http.MethodGet,
+			Header:         http.Header{"Content-Encoding": {"deflate"}},
+			Body:           deflateBytes(data),
+			Expected:       deflateBytes(data),
+			ExpectedAccept: "gzip",
+			Encoding:       "deflate",
+		},
+		{
+			Name:           "Unsupported encoding",
+			Method:         http.MethodGet,
+			Header:         http.Header{"Content-Encoding": {"lzma"}},
+			Body:           data,
+			Expected:       data,
+			ExpectedAccept: "gzip, deflate",
+			Encoding:       "lzma",
+		},
+		{
+			Name:           "Uncompressed body",
+			Method:         http.MethodGet,
+			Body:           data,
+			Expected:       data,
+			ExpectedAccept: "gzip, deflate",
+		},
+		{
+			Name:           "Head request",
+			Method:         http.MethodHead,
+			Header:         http.Header{"Content-Encoding": {"gzip"}},
+			Body:           []byte{},
+			Expected:       []byte{},
+			ExpectedAccept: "gzip, deflate",
+			Encoding:       "gzip",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{
+				StatusCode: 200,
+				Header:     test.Header,
+				Body:       test.Body,
+			}
+			cl := NewClient(mock)
+			cl.Use(Decompress(test.Options))
+
+			req, _ := http.NewRequest(test.Method, "http://localhost:8080/users", nil)
+			if test.AcceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", test.AcceptEncoding)
+			}
+
+			res, err := cl.Do(req)
+			assert.Nil(t, err)
+
+			body, err := io.ReadAll(res.Body)
+			assert.Nil(t, err)
+			assert.Nil(t, res.Body.Close())
+
+			assert.Equal(t, test.Expected, body)
+			assert.Equal(t, test.ExpectedAccept, mock.LastRequest.Header.Get("Accept-Encoding"))
+			assert.Equal(t, test.Encoding, res.Header.Get("Content-Encoding"))
+			assert.Equal(t, test.Uncompressed, res.Uncompressed)
+		})
+	}
+}
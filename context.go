@@ -1,11 +1,22 @@
 package gent
 
 import (
+	"context"
+	"errors"
+	"maps"
 	"net/http"
 	"sync"
+	"time"
 )
 
-// Context stores details about a request. It does not implement context.Context.
+// ErrBodyNotRewindable is returned by Context.RewindBody when the request has
+// a body that can not be recreated for sending the request again, and when a
+// multipart form with a file from a reader that can not seek is written again.
+var ErrBodyNotRewindable = errors.New("request body is not rewindable")
+
+// Context stores details about a request. It implements context.Context by
+// delegating to the context of the request, so it can be passed to functions
+// that take a context.Context.
 type Context struct {
 	cl  Requester
 	mtx *sync.RWMutex
@@ -17,6 +28,11 @@ type Context struct {
 	Response *http.Response
 	Values   map[string]any
 	Errors   []error
+
+	keyed   map[any]any
+	origins []int
+	aborted bool
+	cancels []context.CancelFunc
 }
 
 // newRequestContext creates a request context.
@@ -35,8 +51,10 @@ func newRequestContext(
 	}
 }
 
-// Error appends an error to the context.
+// Error appends an error to the context, and records the middleware that
+// added it.
 func (ctx *Context) Error(err error) {
+	ctx.origins = append(ctx.alignedOrigins(), ctx.fni-1)
 	ctx.Errors = append(ctx.Errors, err)
 }
 
@@ -76,15 +94,155 @@ func (ctx *Context) Del(key string) {
 }
 
 // Next runs the next middleware function on the context. If there are no more
-// functions, it does nothing.
+// functions or the chain was aborted, it does nothing. Calling Next again from
+// the same middleware runs the rest of the chain again, which can be used to
+// retry requests. If the middleware replaced the context of the request, the
+// previous context is restored when it returns.
 func (ctx *Context) Next() {
-	if ctx.fni < len(ctx.fns) {
+	if !ctx.aborted && ctx.fni < len(ctx.fns) {
 		ctx.fni++
+		n, c := len(ctx.cancels), ctx.context()
 		ctx.fns[ctx.fni-1](ctx)
+		ctx.release(n)
+		if ctx.Request != nil && ctx.Request.Context() != c {
+			ctx.Request = ctx.Request.WithContext(c)
+		}
 		ctx.fni--
 	}
 }
 
+// Deadline returns the deadline of the request's context.
+func (ctx *Context) Deadline() (deadline time.Time, ok bool) {
+	return ctx.context().Deadline()
+}
+
+// Done returns the done channel of the request's context.
+func (ctx *Context) Done() <-chan struct{} {
+	return ctx.context().Done()
+}
+
+// Err returns the error of the request's context.
+func (ctx *Context) Err() error {
+	return ctx.context().Err()
+}
+
+// Value returns the value of a key in the request's context. Values in the
+// context's store are not included.
+func (ctx *Context) Value(key any) any {
+	return ctx.context().Value(key)
+}
+
+// context returns the context of the request, or the background context if
+// there is no request.
+func (ctx *Context) context() context.Context {
+	if ctx.Request == nil {
+		return context.Background()
+	}
+	return ctx.Request.Context()
+}
+
+// SetContext replaces the context of the request with c for the rest of the
+// chain, until the middleware that called it returns. The context should be
+// derived from the context of the request, and not from ctx itself, which
+// would make the context refer to itself.
+func (ctx *Context) SetContext(c context.Context) {
+	ctx.Request = ctx.Request.WithContext(c)
+}
+
+// WithTimeout replaces the context of the request with a context that times
+// out after a duration for the rest of the chain, until the middleware that
+// called it returns. The context is then canceled once the response body is
+// closed, or right away if there is no response body.
+func (ctx *Context) WithTimeout(d time.Duration) {
+	c, cancel := context.WithTimeout(ctx.context(), d)
+	ctx.SetContext(c)
+	ctx.cancels = append(ctx.cancels, cancel)
+}
+
+// release cancels the contexts set by WithTimeout after the first n, or
+// defers canceling them until the response body is closed.
+func (ctx *Context) release(n int) {
+	for i := len(ctx.cancels) - 1; i >= n; i-- {
+		res := ctx.Response
+		if res != nil && res.Body != nil {
+			res.Body = &cancelBody{ReadCloser: res.Body, cancel: ctx.cancels[i]}
+		} else {
+			ctx.cancels[i]()
+		}
+	}
+	ctx.cancels = ctx.cancels[:n]
+}
+
+// WithValue replaces the context of the request with a context that carries
+// a value for a key, which is visible to the rest of the chain and the
+// transport.
+func (ctx *Context) WithValue(key, val any) {
+	ctx.SetContext(context.WithValue(ctx.context(), key, val))
+}
+
+// Abort stops the chain, so that calling Next does not run any more
+// middleware functions. Middlewares that already called Next continue after it
+// returns, and can check if the chain was aborted with IsAborted.
+func (ctx *Context) Abort() {
+	ctx.aborted = true
+}
+
+// AbortWithError adds an error to the context and stops the chain.
+func (ctx *Context) AbortWithError(err error) {
+	ctx.Error(err)
+	ctx.Abort()
+}
+
+// AbortWithResponse sets the response of the context and stops the chain,
+// which returns the response without sending the request.
+func (ctx *Context) AbortWithResponse(res *http.Response) {
+	ctx.Response = res
+	ctx.Abort()
+}
+
+// IsAborted reports whether the chain was aborted.
+func (ctx *Context) IsAborted() bool {
+	return ctx.aborted
+}
+
+// fork creates a copy of the context with a request, which can run the rest
+// of the chain independently, such as in another goroutine. The values of the
+// context are copied, while the response and errors are not.
+func (ctx *Context) fork(req *http.Request) *Context {
+	ctx.mtx.RLock()
+	vals := maps.Clone(ctx.Values)
+	keyed := maps.Clone(ctx.keyed)
+	ctx.mtx.RUnlock()
+
+	return &Context{
+		cl:      ctx.cl,
+		mtx:     &sync.RWMutex{},
+		fni:     ctx.fni,
+		fns:     ctx.fns,
+		Request: req,
+		Values:  vals,
+		keyed:   keyed,
+	}
+}
+
+// RewindBody resets the request body with the request's GetBody function so
+// that the request can be sent again. Requests without a body are left as is.
+func (ctx *Context) RewindBody() error {
+	if ctx.Request.Body == nil || ctx.Request.Body == http.NoBody {
+		return nil
+	} else if ctx.Request.GetBody == nil {
+		return ErrBodyNotRewindable
+	}
+
+	body, err := ctx.Request.GetBody()
+	if err != nil {
+		return err
+	}
+
+	ctx.Request.Body = body
+	return nil
+}
+
 // do uses the requester to perform the HTTP request and set the response.
 func do(ctx *Context) {
 	res, err := ctx.cl.Do(ctx.Request)
@@ -2,7 +2,9 @@ package gent
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"net/http"
 	"sync"
 	"testing"
@@ -289,6 +291,199 @@ func TestContextNext(t *testing.T) {
 	}
 }
 
+// TestContextAbort tests stopping the chain of middleware functions.
+func TestContextAbort(t *testing.T) {
+	res := &http.Response{StatusCode: 200}
+	errAbort := fmt.Errorf("aborted")
+
+	tests := []struct {
+		Name     string
+		Abort    func(*Context)
+		Response *http.Response
+		Errors   []error
+	}{
+		{
+			Name:   "Abort",
+			Abort:  func(ctx *Context) { ctx.Abort() },
+			Errors: nil,
+		},
+		{
+			Name:   "Abort with error",
+			Abort:  func(ctx *Context) { ctx.AbortWithError(errAbort) },
+			Errors: []error{errAbort},
+		},
+		{
+			Name:     "Abort with response",
+			Abort:    func(ctx *Context) { ctx.AbortWithResponse(res) },
+			Response: res,
+			Errors:   nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			executed := false
+			aborted := false
+			ctx := newRequestContext(&mockRequester{}, &http.Request{},
+				[]func(*Context){
+					func(ctx *Context) {
+						ctx.Next()
+						aborted = ctx.IsAborted()
+					},
+					func(ctx *Context) {
+						test.Abort(ctx)
+						ctx.Next()
+					},
+					func(ctx *Context) {
+						executed = true
+					},
+				},
+			)
+
+			assert.False(t, ctx.IsAborted())
+			ctx.Next()
+
+			assert.False(t, executed)
+			assert.True(t, aborted)
+			assert.Equal(t, test.Response, ctx.Response)
+			assert.Equal(t, test.Errors, ctx.Errors)
+		})
+	}
+}
+
+// TestContextContext tests using the context as a context.Context.
+func TestContextContext(t *testing.T) {
+	type key struct{}
+
+	tests := []struct {
+		Name     string
+		Update   func(*Context)
+		Deadline bool
+		Value    any
+	}{
+		{
+			Name:   "Request context",
+			Update: func(ctx *Context) {},
+		},
+		{
+			Name: "With timeout",
+			Update: func(ctx *Context) {
+				ctx.WithTimeout(time.Hour)
+			},
+			Deadline: true,
+		},
+		{
+			Name: "With value",
+			Update: func(ctx *Context) {
+				ctx.WithValue(key{}, "value")
+			},
+			Value: "value",
+		},
+		{
+			Name: "Set context",
+			Update: func(ctx *Context) {
+				c, cancel := context.WithDeadline(
+					ctx.Request.Context(), time.Now().Add(time.Hour),
+				)
+				t.Cleanup(cancel)
+				ctx.SetContext(c)
+			},
+			Deadline: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var seen *http.Request
+			mock := &mockRequester{StatusCode: 200}
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+			ctx := newRequestContext(mock, req, []func(*Context){
+				func(ctx *Context) {
+					test.Update(ctx)
+					ctx.Next()
+
+					_, ok := ctx.Deadline()
+					assert.Equal(t, test.Deadline, ok)
+					assert.Equal(t, test.Value, ctx.Value(key{}))
+					assert.Nil(t, ctx.Err())
+				},
+				func(ctx *Context) {
+					seen = ctx.Request
+				},
+			})
+
+			ctx.Next()
+
+			assert.True(t, req.Context() == ctx.Request.Context())
+			assert.Equal(t, test.Value, seen.Context().Value(key{}))
+			_, ok := seen.Context().Deadline()
+			assert.Equal(t, test.Deadline, ok)
+		})
+	}
+}
+
+// TestContextWithTimeout tests canceling the context set by WithTimeout
+// after the middleware that set it returns.
+func TestContextWithTimeout(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Requester *mockRequester
+		Canceled  bool
+	}{
+		{
+			Name:      "Canceled when the body is closed",
+			Requester: &mockRequester{StatusCode: 200, Body: []byte("hello")},
+			Canceled:  false,
+		},
+		{
+			Name:      "Canceled without a response",
+			Requester: &mockRequester{RequestErr: fmt.Errorf("failed")},
+			Canceled:  true,
+		},
+		{
+			Name:      "Canceled when the body is closed with errors",
+			Requester: &mockRequester{StatusCode: 500, Body: []byte("failed")},
+			Canceled:  false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var reqCtx context.Context
+			cl := NewClient(test.Requester)
+			cl.Use(
+				func(ctx *Context) {
+					ctx.WithTimeout(time.Minute)
+					ctx.Next()
+				},
+				StatusCheck(StatusCheckOptions{}),
+				func(ctx *Context) {
+					reqCtx = ctx.Request.Context()
+					ctx.Next()
+				},
+			)
+
+			res, _ := cl.Get("http://localhost:8080")
+
+			_, ok := reqCtx.Deadline()
+			assert.True(t, ok)
+			if test.Canceled {
+				assert.ErrorIs(t, reqCtx.Err(), context.Canceled)
+				return
+			}
+
+			assert.Nil(t, reqCtx.Err())
+			body, err := io.ReadAll(res.Body)
+			assert.Nil(t, err)
+			assert.Equal(t, test.Requester.Body, body)
+			assert.Nil(t, reqCtx.Err())
+
+			res.Body.Close()
+			assert.ErrorIs(t, reqCtx.Err(), context.Canceled)
+		})
+	}
+}
+
 // TestDo tests doing a request
 func TestDo(t *testing.T) {
 	tests := []struct {
@@ -330,3 +525,54 @@ func TestDo(t *testing.T) {
 		})
 	}
 }
+
+// TestContextRewindBody tests resetting the request body.
+func TestContextRewindBody(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Request *http.Request
+		Body    []byte
+		Error   error
+	}{
+		{
+			Name:    "Request without body",
+			Request: &http.Request{},
+			Body:    nil,
+			Error:   nil,
+		},
+		{
+			Name: "Rewindable body",
+			Request: func() *http.Request {
+				req, _ := http.NewRequest(
+					http.MethodPost, "http://localhost:8080",
+					bytes.NewReader([]byte("UserUpdated")),
+				)
+				io.ReadAll(req.Body)
+				return req
+			}(),
+			Body:  []byte("UserUpdated"),
+			Error: nil,
+		},
+		{
+			Name: "Body not rewindable",
+			Request: &http.Request{
+				Body: io.NopCloser(bytes.NewReader([]byte("UserUpdated"))),
+			},
+			Error: ErrBodyNotRewindable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ctx := newRequestContext(&mockRequester{}, test.Request, nil)
+
+			err := ctx.RewindBody()
+
+			assert.Equal(t, test.Error, err)
+			if err == nil && test.Body != nil {
+				body, _ := io.ReadAll(ctx.Request.Body)
+				assert.Equal(t, test.Body, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package gent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Endpoint is a request format that was parsed and validated once. Request
+// builders created from an endpoint only substitute the path parameters when
+// the request is built, without parsing the format again.
+type Endpoint struct {
+	format   string
+	literals []string
+	names    []string
+	named    bool
+	length   int
+}
+
+// CompileFormat parses and validates a request format with positional {} or
+// named {name} placeholders. It returns an error wrapping ErrInvalidFormat
+// if the format has incomplete placeholders, invalid placeholder names, or
+// mixes named and positional placeholders.
+func CompileFormat(format string) (*Endpoint, error) {
+	ep, err := compileFormat(format, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ep, nil
+}
+
+// compileFormat parses and validates a request format into an endpoint. The
+// literals and names of the endpoint are appended to the provided slices, so
+// that callers can compile formats without allocating.
+func compileFormat(
+	format string,
+	literals []string,
+	names []string,
+) (Endpoint, error) {
+	ep := Endpoint{format: format, literals: literals, names: names}
+
+	open, start, cursor := false, 0, 0
+	for i := 0; i < len(format); i++ {
+		ch := format[i]
+		if (open && ch == '{') || (!open && ch == '}') {
+			return Endpoint{}, ErrInvalidFormat
+		} else if ch == '{' {
+			open, start = true, i
+		} else if open && ch != '}' && !isNameChar(ch) {
+			return Endpoint{}, ErrInvalidFormat
+		} else if ch == '}' {
+			open = false
+			name := format[start+1 : i]
+			if len(ep.names) > 0 && ep.named != (name != "") {
+				return Endpoint{}, fmt.Errorf(
+					"%w: mixed named and positional placeholders", ErrInvalidFormat,
+				)
+			}
+
+			ep.named = name != ""
+			ep.names = append(ep.names, name)
+			ep.literals = append(ep.literals, format[cursor:start])
+			ep.length += start - cursor
+			cursor = i + 1
+		}
+	}
+	if open {
+		return Endpoint{}, ErrInvalidFormat
+	}
+
+	ep.literals = append(ep.literals, format[cursor:])
+	ep.length += len(format) - cursor
+	return ep, nil
+}
+
+// MustCompileFormat is like CompileFormat but panics if the format is invalid.
+// It simplifies the initialization of global endpoints.
+func MustCompileFormat(format string) *Endpoint {
+	ep, err := CompileFormat(format)
+	if err != nil {
+		panic(err)
+	}
+	return ep
+}
+
+// Format returns the request format of the endpoint.
+func (ep *Endpoint) Format() string {
+	return ep.format
+}
+
+// NewRequest creates a request builder from the endpoint.
+func (ep *Endpoint) NewRequest(
+	method string,
+) *RequestBuilder {
+	return &RequestBuilder{
+		endpoint: ep,
+		method:   method,
+		format:   ep.format,
+	}
+}
+
+// NewEndpointRequest creates a request builder from the endpoint that sends
+// the request with the client like [Client.NewRequest].
+func (c *Client) NewEndpointRequest(
+	method string,
+	ep *Endpoint,
+) *RequestBuilder {
+	rb := ep.NewRequest(method)
+	rb.client = c
+	return rb
+}
+
+// expand replaces the placeholders of the endpoint with the positional or
+// named path parameters.
+func (ep *Endpoint) expand(
+	pathPrms []string,
+	namedPrms map[string]string,
+) (string, error) {
+	if ep.named && len(pathPrms) > 0 {
+		return "", fmt.Errorf(
+			"%w: positional path parameters for named placeholders",
+			ErrInvalidFormat,
+		)
+	} else if !ep.named && len(pathPrms) != len(ep.names) {
+		return "", ErrInvalidFormat
+	} else if name := ep.unusedParameter(namedPrms); name != "" {
+		return "", fmt.Errorf(
+			"%w: unused path parameter %q", ErrInvalidFormat, name,
+		)
+	}
+
+	buflen := ep.length
+	for _, param := range pathPrms {
+		buflen += len(param)
+	}
+	for _, param := range namedPrms {
+		buflen += len(param)
+	}
+
+	endp := strings.Builder{}
+	endp.Grow(buflen)
+	for i, name := range ep.names {
+		endp.WriteString(ep.literals[i])
+		if !ep.named {
+			endp.WriteString(pathPrms[i])
+		} else if param, ok := namedPrms[name]; ok {
+			endp.WriteString(param)
+		} else {
+			return "", fmt.Errorf(
+				"%w: missing path parameter for placeholder {%s}",
+				ErrInvalidFormat, name,
+			)
+		}
+	}
+
+	endp.WriteString(ep.literals[len(ep.literals)-1])
+	return endp.String(), nil
+}
+
+// unusedParameter returns the first named path parameter in sorted order
+// that has no placeholder in the endpoint.
+func (ep *Endpoint) unusedParameter(
+	namedPrms map[string]string,
+) string {
+	unused := ""
+	for name := range namedPrms {
+		if !slices.Contains(ep.names, name) && (unused == "" || name < unused) {
+			unused = name
+		}
+	}
+	return unused
+}
+
+// isNameChar reports whether the character can be used in the name of a
+// placeholder.
+func isNameChar(ch byte) bool {
+	return (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') ||
+		(ch >= '0' && ch <= '9') || ch == '_'
+}
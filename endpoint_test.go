@@ -0,0 +1,222 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCompileFormat tests parsing and validating request formats.
+func TestCompileFormat(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Format   string
+		Literals []string
+		Names    []string
+		Named    bool
+		Error    string
+	}{
+		{
+			Name:     "Format without placeholders",
+			Format:   "https://localhost:8080/users",
+			Literals: []string{"https://localhost:8080/users"},
+			Names:    nil,
+			Named:    false,
+		},
+		{
+			Name:     "Positional placeholders",
+			Format:   "https://localhost:8080/users/{}/devices/{}",
+			Literals: []string{"https://localhost:8080/users/", "/devices/", ""},
+			Names:    []string{"", ""},
+			Named:    false,
+		},
+		{
+			Name:     "Named placeholders",
+			Format:   "/users/{userId}/orders/{orderId}/items",
+			Literals: []string{"/users/", "/orders/", "/items"},
+			Names:    []string{"userId", "orderId"},
+			Named:    true,
+		},
+		{
+			Name:   "Mixed placeholders",
+			Format: "/users/{}/orders/{orderId}",
+			Error:  "invalid endpoint format: mixed named and positional placeholders",
+		},
+		{
+			Name:   "Unclosed placeholder",
+			Format: "/users/{/orders",
+			Error:  "invalid endpoint format",
+		},
+		{
+			Name:   "Unopened placeholder",
+			Format: "/users/}/orders",
+			Error:  "invalid endpoint format",
+		},
+		{
+			Name:   "Trailing open bracket",
+			Format: "/users/{",
+			Error:  "invalid endpoint format",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			ep, err := CompileFormat(test.Format)
+
+			if test.Error != "" {
+				assert.Nil(t, ep)
+				assert.True(t, errors.Is(err, ErrInvalidFormat))
+				assert.EqualError(t, err, test.Error)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.Format, ep.Format())
+				assert.Equal(t, test.Literals, ep.literals)
+				assert.Equal(t, test.Names, ep.names)
+				assert.Equal(t, test.Named, ep.named)
+			}
+		})
+	}
+}
+
+// TestMustCompileFormat tests panicking on invalid formats.
+func TestMustCompileFormat(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Format string
+		Panics bool
+	}{
+		{
+			Name:   "Valid format",
+			Format: "/users/{}",
+			Panics: false,
+		},
+		{
+			Name:   "Invalid format",
+			Format: "/users/{",
+			Panics: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.Panics {
+				assert.Panics(t, func() { MustCompileFormat(test.Format) })
+			} else {
+				assert.NotPanics(t, func() { MustCompileFormat(test.Format) })
+			}
+		})
+	}
+}
+
+// TestEndpointNewRequest tests building requests from endpoints.
+func TestEndpointNewRequest(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Endpoint *Endpoint
+		Params   []string
+		Named    map[string]string
+		Url      string
+		Error    error
+	}{
+		{
+			Name:     "Positional parameters",
+			Endpoint: MustCompileFormat("https://localhost:8080/users/{}/devices/{}"),
+			Params:   []string{"123", "abc"},
+			Url:      "https://localhost:8080/users/123/devices/abc",
+		},
+		{
+			Name:     "Named parameters",
+			Endpoint: MustCompileFormat("https://localhost:8080/users/{userId}/devices/{deviceId}"),
+			Named:    map[string]string{"deviceId": "abc", "userId": "123"},
+			Url:      "https://localhost:8080/users/123/devices/abc",
+		},
+		{
+			Name:     "Not enough parameters",
+			Endpoint: MustCompileFormat("https://localhost:8080/users/{}/devices/{}"),
+			Params:   []string{"123"},
+			Error:    ErrInvalidFormat,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			rb := test.Endpoint.NewRequest(http.MethodGet)
+			rb.WithPathParameters(test.Params...)
+			for name, val := range test.Named {
+				rb.WithPathParameter(name, val)
+			}
+
+			req, err := rb.Build(context.Background())
+
+			assert.Equal(t, test.Error, err)
+			if err == nil {
+				assert.Equal(t, test.Url, req.URL.String())
+				assert.Equal(t, test.Endpoint.Format(), RequestFormat(req))
+			}
+		})
+	}
+}
+
+// TestClientNewEndpointRequest tests sending requests created from endpoints.
+func TestClientNewEndpointRequest(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Endpoint *Endpoint
+		Url      string
+	}{
+		{
+			Name:     "Send endpoint request",
+			Endpoint: MustCompileFormat("https://localhost:8080/users/{userId}"),
+			Url:      "https://localhost:8080/users/123",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{StatusCode: 200}
+			cl := NewClient(mock)
+
+			_, err := cl.NewEndpointRequest(
+				http.MethodGet, test.Endpoint,
+			).WithPathParameter(
+				"userId", "123",
+			).Send(context.Background())
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Url, mock.LastRequest.URL.String())
+		})
+	}
+}
+
+// BenchmarkBuild benchmarks building a request from a format.
+func BenchmarkBuild(b *testing.B) {
+	rb := NewRequest(http.MethodGet, "http://h/users/{}/orders/{}").
+		WithPathParameters("123", "456")
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := rb.Build(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEndpointBuild benchmarks building a request from a precompiled
+// endpoint.
+func BenchmarkEndpointBuild(b *testing.B) {
+	rb := MustCompileFormat("http://h/users/{}/orders/{}").
+		NewRequest(http.MethodGet).
+		WithPathParameters("123", "456")
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := rb.Build(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package gent
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// MiddlewareError is an error that was added to a Context, with the middleware
+// that added it.
+type MiddlewareError struct {
+	// Err is the error that was added.
+	Err error
+
+	// Index is the position of the middleware in the chain, where the last
+	// position is the function that sends the request. It is -1 if the error
+	// was added without Context.Error.
+	Index int
+
+	// Middleware is the name of the middleware function, such as
+	// "github.com/Soreing/gent.Retry.func1", or empty if it is unknown.
+	Middleware string
+}
+
+// Error returns the message of the error.
+func (e *MiddlewareError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error that was added.
+func (e *MiddlewareError) Unwrap() error {
+	return e.Err
+}
+
+// Errors are the errors that were added to a Context, in the order they were
+// added. It is returned by Client.Do when any middleware adds an error, and it
+// works with errors.Is and errors.As for each error.
+type Errors []*MiddlewareError
+
+// Error returns the messages of the errors on separate lines.
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the errors.
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// errors returns the errors of the context with the middlewares that
+// added them.
+func (ctx *Context) errors() Errors {
+	origins := ctx.alignedOrigins()
+
+	errs := make(Errors, len(ctx.Errors))
+	for i, err := range ctx.Errors {
+		errs[i] = &MiddlewareError{Err: err, Index: origins[i]}
+		if idx := origins[i]; idx >= 0 && idx < len(ctx.fns) {
+			ptr := reflect.ValueOf(ctx.fns[idx]).Pointer()
+			if fn := runtime.FuncForPC(ptr); fn != nil {
+				errs[i].Middleware = fn.Name()
+			}
+		}
+	}
+	return errs
+}
+
+// alignedOrigins returns the positions of the middlewares that added each
+// error, where errors that were added without Error have unknown positions.
+func (ctx *Context) alignedOrigins() []int {
+	origins := ctx.origins[:min(len(ctx.origins), len(ctx.Errors))]
+	for len(origins) < len(ctx.Errors) {
+		origins = append(origins, -1)
+	}
+	return origins
+}
+
+// joinErrors appends the errors of another context with the middlewares
+// that added them.
+func (ctx *Context) joinErrors(src *Context) {
+	ctx.origins = append(ctx.alignedOrigins(), src.alignedOrigins()...)
+	ctx.Errors = append(ctx.Errors, src.Errors...)
+}
@@ -0,0 +1,124 @@
+package gent
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// addError creates a middleware that adds an error to the context.
+func addError(err error) func(*Context) {
+	return func(ctx *Context) {
+		ctx.Error(err)
+		ctx.Next()
+	}
+}
+
+// TestClientDoErrors tests returning every error that was added to the
+// context with the middlewares that added them.
+func TestClientDoErrors(t *testing.T) {
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+	errDirect := errors.New("direct")
+	errRequest := errors.New("request failed")
+
+	tests := []struct {
+		Name        string
+		Middlewares []func(*Context)
+		Requester   *mockRequester
+		Errors      []error
+		Indexes     []int
+		Message     string
+	}{
+		{
+			Name:        "No errors",
+			Middlewares: []func(*Context){},
+			Requester:   &mockRequester{StatusCode: 200},
+		},
+		{
+			Name:        "Errors in order",
+			Middlewares: []func(*Context){addError(errFirst), addError(errSecond)},
+			Requester:   &mockRequester{RequestErr: errRequest},
+			Errors:      []error{errFirst, errSecond, errRequest},
+			Indexes:     []int{0, 1, 2},
+			Message:     "first\nsecond\nrequest failed",
+		},
+		{
+			Name: "Errors added without Error",
+			Middlewares: []func(*Context){
+				func(ctx *Context) {
+					ctx.Errors = append(ctx.Errors, errDirect)
+					ctx.Next()
+				},
+				addError(errFirst),
+			},
+			Requester: &mockRequester{StatusCode: 200},
+			Errors:    []error{errDirect, errFirst},
+			Indexes:   []int{-1, 1},
+			Message:   "direct\nfirst",
+		},
+		{
+			Name: "Errors removed by middleware",
+			Middlewares: []func(*Context){
+				func(ctx *Context) {
+					ctx.Next()
+					ctx.Errors = ctx.Errors[:0]
+					ctx.Next()
+				},
+				addError(errFirst),
+			},
+			Requester: &mockRequester{StatusCode: 200},
+			Errors:    []error{errFirst},
+			Indexes:   []int{1},
+			Message:   "first",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cl := NewClient(test.Requester)
+			cl.Use(test.Middlewares...)
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+			_, err := cl.Do(req)
+			if test.Errors == nil {
+				assert.Nil(t, err)
+				return
+			}
+
+			var errs Errors
+			assert.True(t, errors.As(err, &errs))
+			assert.Equal(t, test.Message, err.Error())
+			assert.Len(t, errs, len(test.Errors))
+			for i, e := range test.Errors {
+				assert.ErrorIs(t, err, e)
+				assert.Equal(t, e, errs[i].Err)
+				assert.Equal(t, test.Indexes[i], errs[i].Index)
+				if test.Indexes[i] < 0 {
+					assert.Equal(t, "", errs[i].Middleware)
+				} else {
+					assert.NotEqual(t, "", errs[i].Middleware)
+				}
+			}
+		})
+	}
+}
+
+// TestClientDoErrorsMiddleware tests the names of the middlewares that
+// added errors.
+func TestClientDoErrorsMiddleware(t *testing.T) {
+	cl := NewClient(&mockRequester{StatusCode: 503})
+	cl.Use(StatusCheck(StatusCheckOptions{}))
+
+	_, err := cl.Get("http://localhost:8080")
+
+	var errs Errors
+	assert.True(t, errors.As(err, &errs))
+	assert.Equal(t, "github.com/Soreing/gent.StatusCheck.func1", errs[0].Middleware)
+
+	var herr *HTTPError
+	assert.True(t, errors.As(err, &herr))
+	assert.Equal(t, 503, herr.StatusCode)
+}
@@ -1,8 +1,11 @@
 package gent
 
 import (
+	"bytes"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"time"
 )
 
@@ -10,11 +13,15 @@ type mockRequester struct {
 	// Request
 	LastRequest *http.Request
 	CountCalled int
+	Bodies      [][]byte
 
 	// Response
-	Delay      time.Duration
-	RequestErr error
-	StatusCode int
+	Delay       time.Duration
+	RequestErr  error
+	StatusCode  int
+	StatusCodes []int
+	Header      http.Header
+	Body        []byte
 
 	// Closed
 	ClosedCount int
@@ -28,6 +35,12 @@ func (m *mockRequester) Do(r *http.Request) (*http.Response, error) {
 	m.CountCalled++
 	m.LastRequest = r
 
+	if r.Body != nil {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		m.Bodies = append(m.Bodies, body)
+	}
+
 	time.Sleep(m.Delay)
 
 	if m.RequestErr != nil {
@@ -36,6 +49,24 @@ func (m *mockRequester) Do(r *http.Request) (*http.Response, error) {
 		rec := httptest.NewRecorder()
 		res := rec.Result()
 		res.StatusCode = m.StatusCode
+		if len(m.StatusCodes) > 0 {
+			res.StatusCode = m.StatusCodes[0]
+			m.StatusCodes = m.StatusCodes[1:]
+		}
+		for key, vals := range m.Header {
+			res.Header[key] = vals
+		}
+		if m.Body != nil {
+			res.Body = io.NopCloser(bytes.NewReader(m.Body))
+		}
 		return res, nil
 	}
 }
+
+func mustParseURL(raw string) *url.URL {
+	u, err := url.Parse(raw)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
@@ -0,0 +1,239 @@
+package gent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// HedgeOptions configures the Hedge middleware.
+type HedgeOptions struct {
+	// MaxAttempts is the maximum number of copies of a request that are sent,
+	// including the first one. Defaults to 2.
+	MaxAttempts int
+
+	// Percentile is the percentile of the latencies of a host that is used as
+	// the delay before sending another copy, between 0 and 1. Defaults to 0.95.
+	Percentile float64
+
+	// Delay is the delay before sending another copy while a host does not
+	// have enough latency samples. Defaults to 100 milliseconds.
+	Delay time.Duration
+
+	// Window is the number of recent latencies that are tracked per host.
+	// Defaults to 100.
+	Window int
+
+	// Methods are the request methods that are hedged. Defaults to GET, HEAD
+	// and OPTIONS.
+	Methods []string
+}
+
+// minLatencySamples is the number of latencies a host needs before its
+// percentile is used as the delay.
+const minLatencySamples = 10
+
+// hedgeResult is the outcome of a copy of a hedged request.
+type hedgeResult struct {
+	ctx     *Context
+	attempt int
+	latency time.Duration
+}
+
+// Hedge creates a middleware that sends another copy of an idempotent request
+// if it has not completed within a delay, and returns the first successful
+// response. The delay is a percentile of the recent latencies of the request's
+// host. Other copies are cancelled, and the bodies of their responses are
+// closed. Requests with bodies are only hedged if their bodies can be
+// recreated with GetBody.
+func Hedge(opts HedgeOptions) func(*Context) {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 2
+	}
+	if opts.Percentile <= 0 || opts.Percentile > 1 {
+		opts.Percentile = 0.95
+	}
+	if opts.Delay <= 0 {
+		opts.Delay = 100 * time.Millisecond
+	}
+	if opts.Window <= 0 {
+		opts.Window = 100
+	}
+	if len(opts.Methods) == 0 {
+		opts.Methods = []string{
+			http.MethodGet, http.MethodHead, http.MethodOptions,
+		}
+	}
+
+	mtx := sync.Mutex{}
+	hosts := map[string]*latencies{}
+
+	return func(ctx *Context) {
+		req := ctx.Request
+		hasBody := req.Body != nil && req.Body != http.NoBody
+		if !contains(opts.Methods, req.Method) ||
+			(hasBody && req.GetBody == nil) {
+			ctx.Next()
+			return
+		}
+
+		mtx.Lock()
+		lat, ok := hosts[req.URL.Host]
+		if !ok {
+			lat = &latencies{samples: make([]time.Duration, 0, opts.Window)}
+			hosts[req.URL.Host] = lat
+		}
+		mtx.Unlock()
+
+		delay, ok := lat.percentile(opts.Percentile)
+		if !ok {
+			delay = opts.Delay
+		}
+
+		results := make(chan *hedgeResult, opts.MaxAttempts)
+		cancels := make([]context.CancelFunc, 0, opts.MaxAttempts)
+		send := func() bool {
+			rctx, cancel := context.WithCancel(req.Context())
+			r := req.Clone(rctx)
+			if hasBody && len(cancels) > 0 {
+				body, err := req.GetBody()
+				if err != nil {
+					cancel()
+					return false
+				}
+				r.Body = body
+			}
+
+			attempt := len(cancels)
+			cancels = append(cancels, cancel)
+
+			fork := ctx.fork(r)
+			go func() {
+				start := time.Now()
+				fork.Next()
+				results <- &hedgeResult{
+					ctx:     fork,
+					attempt: attempt,
+					latency: time.Since(start),
+				}
+			}()
+			return true
+		}
+
+		send()
+		pending := 1
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		var winner *hedgeResult
+		for winner == nil {
+			select {
+			case res := <-results:
+				pending--
+				if res.succeeded() || pending == 0 {
+					winner = res
+				} else {
+					res.close(cancels)
+				}
+			case <-timer.C:
+				if len(cancels) < opts.MaxAttempts && send() {
+					pending++
+					timer.Reset(delay)
+				}
+			}
+		}
+
+		for i, cancel := range cancels {
+			if i != winner.attempt {
+				cancel()
+			}
+		}
+		go func() {
+			for ; pending > 0; pending-- {
+				(<-results).close(cancels)
+			}
+		}()
+
+		if winner.succeeded() {
+			lat.add(winner.latency)
+		}
+		ctx.joinErrors(winner.ctx)
+		if winner.ctx.IsAborted() {
+			ctx.Abort()
+		}
+
+		cancel := cancels[winner.attempt]
+		if res := winner.ctx.Response; res != nil && res.Body != nil {
+			res.Body = &cancelBody{ReadCloser: res.Body, cancel: cancel}
+		} else {
+			cancel()
+		}
+		ctx.Response = winner.ctx.Response
+	}
+}
+
+// succeeded checks if the copy of the request got a response without errors.
+func (r *hedgeResult) succeeded() bool {
+	return r.ctx.Response != nil && len(r.ctx.Errors) == 0
+}
+
+// close closes the body of the response of a copy of the request that was
+// not used, and cancels its context.
+func (r *hedgeResult) close(cancels []context.CancelFunc) {
+	if res := r.ctx.Response; res != nil && res.Body != nil {
+		res.Body.Close()
+	}
+	cancels[r.attempt]()
+}
+
+// cancelBody is a response body that cancels the context of its request when
+// it is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the context of the request.
+func (b *cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// latencies tracks the recent latencies of a host in a ring buffer.
+type latencies struct {
+	mtx     sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// add records a latency, replacing the oldest one if the buffer is full.
+func (l *latencies) add(d time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if len(l.samples) < cap(l.samples) {
+		l.samples = append(l.samples, d)
+	} else {
+		l.samples[l.next] = d
+		l.next = (l.next + 1) % len(l.samples)
+	}
+}
+
+// percentile returns a percentile of the recorded latencies, or false if
+// there are not enough samples.
+func (l *latencies) percentile(p float64) (time.Duration, bool) {
+	l.mtx.Lock()
+	samples := slices.Clone(l.samples)
+	l.mtx.Unlock()
+
+	if len(samples) < minLatencySamples {
+		return 0, false
+	}
+
+	slices.Sort(samples)
+	idx := int(p*float64(len(samples))+0.5) - 1
+	return samples[max(0, min(idx, len(samples)-1))], true
+}
@@ -0,0 +1,203 @@
+package gent
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// trackedBody is a response body that records if it was closed.
+type trackedBody struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+// Close marks the body as closed.
+func (b *trackedBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+// TestHedge tests sending copies of slow requests.
+func TestHedge(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Options  HedgeOptions
+		Method   string
+		Delays   []time.Duration
+		Calls    int32
+		Winner   string
+		Bodies   int
+		MaxTotal time.Duration
+	}{
+		{
+			Name:     "Fast response",
+			Options:  HedgeOptions{Delay: 50 * time.Millisecond},
+			Method:   http.MethodGet,
+			Delays:   []time.Duration{0, 0},
+			Calls:    1,
+			Winner:   "0",
+			Bodies:   1,
+			MaxTotal: 40 * time.Millisecond,
+		},
+		{
+			Name:     "Slow response hedged",
+			Options:  HedgeOptions{Delay: 20 * time.Millisecond},
+			Method:   http.MethodGet,
+			Delays:   []time.Duration{300 * time.Millisecond, 0},
+			Calls:    2,
+			Winner:   "1",
+			Bodies:   2,
+			MaxTotal: 200 * time.Millisecond,
+		},
+		{
+			Name:     "Third copy",
+			Options:  HedgeOptions{Delay: 20 * time.Millisecond, MaxAttempts: 3},
+			Method:   http.MethodGet,
+			Delays:   []time.Duration{300 * time.Millisecond, 300 * time.Millisecond, 0},
+			Calls:    3,
+			Winner:   "2",
+			Bodies:   3,
+			MaxTotal: 200 * time.Millisecond,
+		},
+		{
+			Name:     "Unsafe method",
+			Options:  HedgeOptions{Delay: 20 * time.Millisecond},
+			Method:   http.MethodPost,
+			Delays:   []time.Duration{100 * time.Millisecond, 0},
+			Calls:    1,
+			Winner:   "0",
+			Bodies:   1,
+			MaxTotal: time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			calls := atomic.Int32{}
+			mtx := sync.Mutex{}
+			bodies := []*trackedBody{}
+
+			cl := NewClient(&mockRequester{})
+			cl.Use(Hedge(test.Options))
+			cl.Use(func(ctx *Context) {
+				attempt := calls.Add(1) - 1
+				time.Sleep(test.Delays[attempt])
+
+				body := &trackedBody{Reader: strings.NewReader(string('0' + rune(attempt)))}
+				mtx.Lock()
+				bodies = append(bodies, body)
+				mtx.Unlock()
+
+				ctx.Response = &http.Response{StatusCode: 200, Body: body}
+			})
+
+			start := time.Now()
+			req, _ := http.NewRequest(test.Method, "http://localhost:8080/users", nil)
+			res, err := cl.Do(req)
+			elapsed := time.Since(start)
+
+			assert.Nil(t, err)
+			body, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+
+			assert.Equal(t, test.Winner, string(body))
+			assert.Less(t, elapsed, test.MaxTotal)
+			assert.Eventually(t, func() bool {
+				mtx.Lock()
+				defer mtx.Unlock()
+				if len(bodies) != test.Bodies {
+					return false
+				}
+				for _, b := range bodies {
+					if !b.closed.Load() {
+						return false
+					}
+				}
+				return true
+			}, time.Second, 10*time.Millisecond)
+			assert.Equal(t, test.Calls, calls.Load())
+		})
+	}
+}
+
+// TestHedgeBody tests that copies of requests get their own body.
+func TestHedgeBody(t *testing.T) {
+	calls := atomic.Int32{}
+	received := make(chan []byte, 2)
+
+	cl := NewClient(&mockRequester{})
+	cl.Use(Hedge(HedgeOptions{Delay: 10 * time.Millisecond}))
+	cl.Use(func(ctx *Context) {
+		attempt := calls.Add(1) - 1
+		body, _ := io.ReadAll(ctx.Request.Body)
+		received <- body
+		if attempt == 0 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		ctx.Response = &http.Response{StatusCode: 200, Body: http.NoBody}
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/users", bytes.NewReader([]byte("gent")))
+	_, err := cl.Do(req)
+	assert.Nil(t, err)
+
+	assert.Equal(t, []byte("gent"), <-received)
+	assert.Equal(t, []byte("gent"), <-received)
+}
+
+// TestLatencies tests the percentiles of tracked latencies.
+func TestLatencies(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Samples    int
+		Window     int
+		Percentile float64
+		Expected   time.Duration
+		Ok         bool
+	}{
+		{
+			Name:       "Not enough samples",
+			Samples:    5,
+			Window:     100,
+			Percentile: 0.95,
+			Ok:         false,
+		},
+		{
+			Name:       "95th percentile",
+			Samples:    100,
+			Window:     100,
+			Percentile: 0.95,
+			Expected:   95 * time.Millisecond,
+			Ok:         true,
+		},
+		{
+			Name:       "Median of recent samples",
+			Samples:    200,
+			Window:     100,
+			Percentile: 0.5,
+			Expected:   150 * time.Millisecond,
+			Ok:         true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			lat := &latencies{samples: make([]time.Duration, 0, test.Window)}
+			for i := 1; i <= test.Samples; i++ {
+				lat.add(time.Duration(i) * time.Millisecond)
+			}
+
+			d, ok := lat.percentile(test.Percentile)
+			assert.Equal(t, test.Ok, ok)
+			assert.Equal(t, test.Expected, d)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package gent
+
+// Key is a typed key of a value in a request context. Keys are compared by
+// identity instead of by name, so keys of different packages never collide.
+// Values of keys are stored separately from the string keyed Values.
+type Key[T any] struct {
+	name string
+}
+
+// NewKey creates a key for values of type T. The name is only used to
+// describe the key.
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+// String returns the name of the key.
+func (k *Key[T]) String() string {
+	return k.name
+}
+
+// Get retrieves the value of the key from the context. The operation locks
+// the context's mutex for thread safety.
+func (k *Key[T]) Get(ctx *Context) (val T, ok bool) {
+	ctx.mtx.RLock()
+	v, ok := ctx.keyed[k]
+	ctx.mtx.RUnlock()
+
+	if v != nil {
+		val = v.(T)
+	}
+	return val, ok
+}
+
+// Set assigns a value to the key in the context. The operation locks the
+// context's mutex for thread safety.
+func (k *Key[T]) Set(ctx *Context, val T) {
+	ctx.mtx.Lock()
+	ctx.setKeyed(k, val)
+	ctx.mtx.Unlock()
+}
+
+// Delete removes the value of the key from the context. The operation locks
+// the context's mutex for thread safety.
+func (k *Key[T]) Delete(ctx *Context) {
+	ctx.mtx.Lock()
+	delete(ctx.keyed, k)
+	ctx.mtx.Unlock()
+}
+
+// Value pairs the key with a value, which can be used to set the value in the
+// context of a request with [RequestBuilder.WithValues] or
+// [Client.DoWithValues].
+func (k *Key[T]) Value(val T) KeyValue {
+	return KeyValue{key: k, val: val}
+}
+
+// KeyValue is a value of a typed key created by [Key.Value].
+type KeyValue struct {
+	key any
+	val any
+}
+
+// setKeyed assigns a value to a typed key without locking the mutex.
+func (ctx *Context) setKeyed(key, val any) {
+	if ctx.keyed == nil {
+		ctx.keyed = map[any]any{}
+	}
+	ctx.keyed[key] = val
+}
+
+// setValues assigns the values of typed keys to the context. Later values
+// overwrite earlier values of the same key.
+func (ctx *Context) setValues(vals []KeyValue) {
+	if len(vals) == 0 {
+		return
+	}
+
+	ctx.mtx.Lock()
+	for _, kv := range vals {
+		if kv.key != nil {
+			ctx.setKeyed(kv.key, kv.val)
+		}
+	}
+	ctx.mtx.Unlock()
+}
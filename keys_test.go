@@ -0,0 +1,189 @@
+package gent
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestKey tests getting, setting and deleting values of typed keys.
+func TestKey(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Update func(*Context, *Key[int])
+		Value  int
+		Ok     bool
+	}{
+		{
+			Name:   "Missing value",
+			Update: func(ctx *Context, key *Key[int]) {},
+			Value:  0,
+			Ok:     false,
+		},
+		{
+			Name: "Set value",
+			Update: func(ctx *Context, key *Key[int]) {
+				key.Set(ctx, 42)
+			},
+			Value: 42,
+			Ok:    true,
+		},
+		{
+			Name: "Overwrite value",
+			Update: func(ctx *Context, key *Key[int]) {
+				key.Set(ctx, 1)
+				key.Set(ctx, 2)
+			},
+			Value: 2,
+			Ok:    true,
+		},
+		{
+			Name: "Delete value",
+			Update: func(ctx *Context, key *Key[int]) {
+				key.Set(ctx, 1)
+				key.Delete(ctx)
+			},
+			Value: 0,
+			Ok:    false,
+		},
+		{
+			Name: "Key with the same name",
+			Update: func(ctx *Context, key *Key[int]) {
+				NewKey[int](key.String()).Set(ctx, 7)
+			},
+			Value: 0,
+			Ok:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			key := NewKey[int]("count")
+			ctx := newRequestContext(&mockRequester{}, &http.Request{}, nil)
+
+			test.Update(ctx, key)
+			val, ok := key.Get(ctx)
+
+			assert.Equal(t, test.Value, val)
+			assert.Equal(t, test.Ok, ok)
+			assert.Empty(t, ctx.Values)
+		})
+	}
+}
+
+// TestKeyNilValue tests getting nil values of keys with interface types.
+func TestKeyNilValue(t *testing.T) {
+	tests := []struct {
+		Name string
+	}{
+		{Name: "Nil error"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			key := NewKey[error]("error")
+			ctx := newRequestContext(&mockRequester{}, &http.Request{}, nil)
+
+			key.Set(ctx, nil)
+			val, ok := key.Get(ctx)
+
+			assert.Nil(t, val)
+			assert.True(t, ok)
+		})
+	}
+}
+
+// TestKeyConcurrent tests using keys from multiple goroutines.
+func TestKeyConcurrent(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Goroutines int
+	}{
+		{Name: "Concurrent access", Goroutines: 8},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			key := NewKey[int]("count")
+			ctx := newRequestContext(&mockRequester{}, &http.Request{}, nil)
+
+			wg := sync.WaitGroup{}
+			for i := 0; i < test.Goroutines; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					key.Set(ctx, i)
+					key.Get(ctx)
+				}(i)
+			}
+			wg.Wait()
+
+			_, ok := key.Get(ctx)
+			assert.True(t, ok)
+		})
+	}
+}
+
+// TestKeyValues tests seeding values of keys for a request.
+func TestKeyValues(t *testing.T) {
+	user := NewKey[string]("user")
+	tenant := NewKey[int]("tenant")
+
+	tests := []struct {
+		Name    string
+		Builder []KeyValue
+		Do      []KeyValue
+		User    string
+		Tenant  int
+	}{
+		{
+			Name: "No values",
+		},
+		{
+			Name:    "Request builder values",
+			Builder: []KeyValue{user.Value("john"), tenant.Value(3)},
+			User:    "john",
+			Tenant:  3,
+		},
+		{
+			Name:   "Do values",
+			Do:     []KeyValue{user.Value("jane")},
+			User:   "jane",
+			Tenant: 0,
+		},
+		{
+			Name:    "Do values overwrite builder values",
+			Builder: []KeyValue{user.Value("john"), tenant.Value(3)},
+			Do:      []KeyValue{user.Value("jane")},
+			User:    "jane",
+			Tenant:  3,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var gotUser string
+			var gotTenant int
+
+			cl := NewClient(&mockRequester{StatusCode: 200})
+			cl.Use(func(ctx *Context) {
+				gotUser, _ = user.Get(ctx)
+				gotTenant, _ = tenant.Get(ctx)
+				ctx.Next()
+			})
+
+			req, err := NewRequest(http.MethodGet, "http://localhost:8080").
+				WithValues(test.Builder...).
+				Build(context.Background())
+			assert.Nil(t, err)
+
+			_, err = cl.DoWithValues(req, test.Do...)
+			assert.Nil(t, err)
+			assert.Equal(t, test.User, gotUser)
+			assert.Equal(t, test.Tenant, gotTenant)
+		})
+	}
+}
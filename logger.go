@@ -0,0 +1,254 @@
+package gent
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redacted replaces the values of redacted headers and query parameters.
+const redacted = "REDACTED"
+
+// LogOptions configures the Log middleware.
+type LogOptions struct {
+	// Logger writes the logs. Defaults to slog.Default().
+	Logger *slog.Logger
+
+	// Message is the message of the logs. Defaults to "http request".
+	Message string
+
+	// SuccessLevel is the level of requests with 1xx, 2xx and 3xx responses.
+	// Defaults to slog.LevelInfo.
+	SuccessLevel slog.Leveler
+
+	// ClientErrorLevel is the level of requests with 4xx responses. Defaults
+	// to slog.LevelWarn.
+	ClientErrorLevel slog.Leveler
+
+	// ServerErrorLevel is the level of requests with 5xx responses. Defaults
+	// to slog.LevelError.
+	ServerErrorLevel slog.Leveler
+
+	// ErrorLevel is the level of requests with errors in the context.
+	// Defaults to slog.LevelError.
+	ErrorLevel slog.Leveler
+
+	// Headers logs the request and response headers.
+	Headers bool
+
+	// RedactHeaders are the headers whose values are redacted. Defaults to
+	// Authorization, Proxy-Authorization, Cookie, Set-Cookie and X-Api-Key.
+	RedactHeaders []string
+
+	// RedactQuery are the query parameters whose values are redacted. Defaults
+	// to api_key, apikey, access_token, token and signature.
+	RedactQuery []string
+
+	// MaxBodySize is the number of bytes of the request and response bodies
+	// that are logged. Bodies are not logged if it is zero. Response bodies
+	// are captured as they are read, so the log is delayed until the body is
+	// read to the end or closed.
+	MaxBodySize int64
+}
+
+// Log creates a middleware that logs requests with their method, URL, status
+// code, duration and the errors in the context, at a level depending on the
+// outcome. Values of sensitive headers and query parameters are redacted.
+// Logged bodies are captured without consuming them, so they can still be
+// read by other middlewares and the caller. When the response body is logged,
+// the log is written once the caller reads the body to the end or closes it.
+func Log(opts LogOptions) func(*Context) {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	if opts.Message == "" {
+		opts.Message = "http request"
+	}
+	if opts.SuccessLevel == nil {
+		opts.SuccessLevel = slog.LevelInfo
+	}
+	if opts.ClientErrorLevel == nil {
+		opts.ClientErrorLevel = slog.LevelWarn
+	}
+	if opts.ServerErrorLevel == nil {
+		opts.ServerErrorLevel = slog.LevelError
+	}
+	if opts.ErrorLevel == nil {
+		opts.ErrorLevel = slog.LevelError
+	}
+	if opts.RedactHeaders == nil {
+		opts.RedactHeaders = []string{
+			"Authorization", "Proxy-Authorization",
+			"Cookie", "Set-Cookie", "X-Api-Key",
+		}
+	}
+	if opts.RedactQuery == nil {
+		opts.RedactQuery = []string{
+			"api_key", "apikey", "access_token", "token", "signature",
+		}
+	}
+
+	return func(ctx *Context) {
+		req := ctx.Request
+
+		var reqBody *captureBody
+		if opts.MaxBodySize > 0 && req.Body != nil && req.Body != http.NoBody {
+			reqBody = &captureBody{ReadCloser: req.Body, limit: opts.MaxBodySize}
+			ctx.Request = req.WithContext(req.Context())
+			ctx.Request.Body = reqBody
+		}
+
+		errc := len(ctx.Errors)
+		start := time.Now()
+		ctx.Next()
+		duration := time.Since(start)
+		ctx.Request = req
+
+		res := ctx.Response
+		errs := ctx.Errors[errc:]
+
+		level := opts.SuccessLevel.Level()
+		switch {
+		case len(errs) > 0 || res == nil:
+			level = opts.ErrorLevel.Level()
+		case res.StatusCode >= 500:
+			level = opts.ServerErrorLevel.Level()
+		case res.StatusCode >= 400:
+			level = opts.ClientErrorLevel.Level()
+		}
+		if !opts.Logger.Enabled(req.Context(), level) {
+			return
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("url", redactURL(req.URL, opts.RedactQuery)),
+			slog.Duration("duration", duration),
+		}
+		if res != nil {
+			attrs = append(attrs, slog.Int("status", res.StatusCode))
+		}
+		if len(errs) > 0 {
+			msgs := make([]string, len(errs))
+			for i, err := range errs {
+				msgs[i] = err.Error()
+			}
+			attrs = append(attrs, slog.Any("errors", msgs))
+		}
+
+		if opts.Headers {
+			attrs = append(attrs, headerAttr("request_headers", req.Header, opts.RedactHeaders))
+			if res != nil {
+				attrs = append(attrs, headerAttr("response_headers", res.Header, opts.RedactHeaders))
+			}
+		}
+
+		if reqBody != nil {
+			attrs = append(attrs, slog.String("request_body", reqBody.String()))
+		}
+		if opts.MaxBodySize > 0 && res != nil && res.Body != nil && res.Body != http.NoBody {
+			resBody := &captureBody{ReadCloser: res.Body, limit: opts.MaxBodySize}
+			resBody.done = func() {
+				attrs = append(attrs, slog.String("response_body", resBody.String()))
+				opts.Logger.LogAttrs(req.Context(), level, opts.Message, attrs...)
+			}
+			res.Body = resBody
+			return
+		}
+
+		opts.Logger.LogAttrs(req.Context(), level, opts.Message, attrs...)
+	}
+}
+
+// redactURL returns the URL with redacted user credentials and values of
+// query parameters.
+func redactURL(u *url.URL, keys []string) string {
+	ru := *u
+	if ru.User != nil {
+		ru.User = url.User(redacted)
+	}
+
+	if ru.RawQuery != "" {
+		q := ru.Query()
+		changed := false
+		for key, vals := range q {
+			if contains(keys, key) {
+				for i := range vals {
+					vals[i] = redacted
+				}
+				changed = true
+			}
+		}
+		if changed {
+			ru.RawQuery = q.Encode()
+		}
+	}
+	return ru.String()
+}
+
+// headerAttr returns a group attribute of headers with redacted values.
+func headerAttr(name string, hdr http.Header, keys []string) slog.Attr {
+	attrs := make([]any, 0, len(hdr))
+	for key, vals := range hdr {
+		val := strings.Join(vals, ", ")
+		if contains(keys, key) {
+			val = redacted
+		}
+		attrs = append(attrs, slog.String(key, val))
+	}
+	return slog.Group(name, attrs...)
+}
+
+// captureBody is a body that keeps a copy of the first bytes that are read
+// from it, up to a limit. If done is set, it is called once when the body is
+// read to the end or closed.
+type captureBody struct {
+	io.ReadCloser
+	mtx   sync.Mutex
+	buf   bytes.Buffer
+	limit int64
+	done  func()
+	once  sync.Once
+}
+
+// Read reads from the body and copies the bytes within the limit.
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+
+	b.mtx.Lock()
+	if rem := b.limit - int64(b.buf.Len()); rem > 0 {
+		b.buf.Write(p[:min(int64(n), rem)])
+	}
+	b.mtx.Unlock()
+
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+// Close closes the body.
+func (b *captureBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish()
+	return err
+}
+
+// finish calls done once, if it is set.
+func (b *captureBody) finish() {
+	if b.done != nil {
+		b.once.Do(b.done)
+	}
+}
+
+// String returns the bytes that were copied.
+func (b *captureBody) String() string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.String()
+}
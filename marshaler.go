@@ -3,6 +3,7 @@ package gent
 import (
 	"encoding/json"
 	"encoding/xml"
+	"io"
 	"net/url"
 )
 
@@ -40,3 +41,62 @@ func UrlEncodedMarshaler(body any) (dat []byte, hdrs map[string][]string, err er
 	hdrs = map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}}
 	return
 }
+
+// StreamMarshaler defines how to encode an object directly into the writer of
+// a request's body, with additional optional headers to set. It returns the
+// function that encodes the object, which can be called more than once.
+type StreamMarshaler func(body any) (func(io.Writer) error, map[string][]string, error)
+
+// JsonStreamMarshaler uses the standard encoding/json encoder to write the
+// json encoded body, and returns a Content-Type application/json header.
+func JsonStreamMarshaler(body any) (enc func(io.Writer) error, hdrs map[string][]string, err error) {
+	hdrs = map[string][]string{"Content-Type": {"application/json"}}
+	enc = func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(body)
+	}
+	return
+}
+
+// XmlStreamMarshaler uses the standard encoding/xml encoder to write the
+// xml encoded body, and returns a Content-Type application/xml header.
+func XmlStreamMarshaler(body any) (enc func(io.Writer) error, hdrs map[string][]string, err error) {
+	hdrs = map[string][]string{"Content-Type": {"application/xml"}}
+	enc = func(w io.Writer) error {
+		return xml.NewEncoder(w).Encode(body)
+	}
+	return
+}
+
+// Unmarshaler defines how to process the byte array of a response's body
+// into an object.
+type Unmarshaler func(data []byte, v any) error
+
+// JsonUnmarshaler uses the standard encoding/json unmarshaler to decode the
+// json encoded body into the object.
+func JsonUnmarshaler(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// XmlUnmarshaler uses the standard encoding/xml unmarshaler to decode the
+// xml encoded body into the object.
+func XmlUnmarshaler(data []byte, v any) error {
+	return xml.Unmarshal(data, v)
+}
+
+// UrlEncodedUnmarshaler uses the standard net/url decoder to decode the
+// url encoded body into a *url.Values or *map[string][]string object.
+func UrlEncodedUnmarshaler(data []byte, v any) error {
+	vals, err := url.ParseQuery(string(data))
+	if err != nil {
+		return err
+	}
+
+	if ptr, ok := v.(*url.Values); ok && ptr != nil {
+		*ptr = vals
+	} else if ptr, ok := v.(*map[string][]string); ok && ptr != nil {
+		*ptr = vals
+	} else {
+		return ErrInvalidBodyType
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package gent
 
 import (
+	"bytes"
 	"net/url"
 	"testing"
 
@@ -182,3 +183,165 @@ func TestUrlEncodedMarshaler(t *testing.T) {
 		})
 	}
 }
+
+// TestJsonUnmarshaler tests unmarshaling JSON into objects.
+func TestJsonUnmarshaler(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Bytes  []byte
+		Object map[string]any
+		Error  bool
+	}{
+		{
+			Name:   "Unmarshal object",
+			Bytes:  []byte(`{"id":123,"name":"John Smith"}`),
+			Object: map[string]any{"id": float64(123), "name": "John Smith"},
+			Error:  false,
+		},
+		{
+			Name:  "Unmarshal invalid json",
+			Bytes: []byte(`{"id":`),
+			Error: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var obj map[string]any
+			err := JsonUnmarshaler(test.Bytes, &obj)
+
+			if test.Error {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.Object, obj)
+			}
+		})
+	}
+}
+
+// TestXmlUnmarshaler tests unmarshaling XML into objects.
+func TestXmlUnmarshaler(t *testing.T) {
+	type user struct {
+		Id   int    `xml:"id"`
+		Name string `xml:"name"`
+	}
+
+	tests := []struct {
+		Name   string
+		Bytes  []byte
+		Object user
+		Error  bool
+	}{
+		{
+			Name:   "Unmarshal object",
+			Bytes:  []byte(`<user><id>123</id><name>John Smith</name></user>`),
+			Object: user{Id: 123, Name: "John Smith"},
+			Error:  false,
+		},
+		{
+			Name:  "Unmarshal invalid xml",
+			Bytes: []byte(`<user><id>`),
+			Error: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var obj user
+			err := XmlUnmarshaler(test.Bytes, &obj)
+
+			if test.Error {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.Object, obj)
+			}
+		})
+	}
+}
+
+// TestUrlEncodedUnmarshaler tests unmarshaling URL encoded forms into objects.
+func TestUrlEncodedUnmarshaler(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Bytes  []byte
+		Object any
+		Values url.Values
+		Error  error
+	}{
+		{
+			Name:   "Unmarshal url values",
+			Bytes:  []byte("id=123&name=John+Smith"),
+			Object: &url.Values{},
+			Values: url.Values{"id": {"123"}, "name": {"John Smith"}},
+			Error:  nil,
+		},
+		{
+			Name:   "Unmarshal map",
+			Bytes:  []byte("id=123&name=John+Smith"),
+			Object: &map[string][]string{},
+			Values: url.Values{"id": {"123"}, "name": {"John Smith"}},
+			Error:  nil,
+		},
+		{
+			Name:   "Unmarshal invalid type",
+			Bytes:  []byte("id=123"),
+			Object: &map[string]string{},
+			Error:  ErrInvalidBodyType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := UrlEncodedUnmarshaler(test.Bytes, test.Object)
+
+			assert.Equal(t, test.Error, err)
+			switch obj := test.Object.(type) {
+			case *url.Values:
+				assert.Equal(t, test.Values, *obj)
+			case *map[string][]string:
+				assert.Equal(t, map[string][]string(test.Values), *obj)
+			}
+		})
+	}
+}
+
+// TestStreamMarshalers tests encoding objects into writers.
+func TestStreamMarshalers(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Marshaler StreamMarshaler
+		Object    any
+		Bytes     []byte
+		Headers   map[string][]string
+	}{
+		{
+			Name:      "Json stream",
+			Marshaler: JsonStreamMarshaler,
+			Object:    map[string]any{"id": 123},
+			Bytes:     []byte("{\"id\":123}\n"),
+			Headers:   map[string][]string{"Content-Type": {"application/json"}},
+		},
+		{
+			Name:      "Xml stream",
+			Marshaler: XmlStreamMarshaler,
+			Object:    user{Id: 123, Name: "John Smith"},
+			Bytes:     []byte("<user><id>123</id><name>John Smith</name></user>"),
+			Headers:   map[string][]string{"Content-Type": {"application/xml"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			enc, hdrs, err := test.Marshaler(test.Object)
+			assert.Nil(t, err)
+			assert.Equal(t, test.Headers, hdrs)
+
+			buf := bytes.Buffer{}
+			err = enc(&buf)
+			assert.Nil(t, err)
+			assert.Equal(t, string(test.Bytes), buf.String())
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package gent
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MetricLabels are the labels of the metrics of a request. The route is the
+// path of the format of the RequestBuilder that built the request, which keeps
+// the cardinality of the labels bounded. The status class is the class of the
+// response status code such as "2xx", or "error" if there is no response.
+type MetricLabels struct {
+	Method      string
+	Host        string
+	Route       string
+	StatusClass string
+}
+
+// Metrics records the metrics of requests made by the Instrument middleware.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// RequestStarted is called before a request is sent, with labels that
+	// have no status class.
+	RequestStarted(labels MetricLabels)
+
+	// RequestFinished is called when the response of a request is received
+	// or the request failed, with the duration of the request and the size of
+	// the request body.
+	RequestFinished(labels MetricLabels, duration time.Duration, requestSize int64)
+
+	// ResponseRead is called when the body of a response is read to the end
+	// or closed, with the number of bytes read.
+	ResponseRead(labels MetricLabels, responseSize int64)
+}
+
+// MetricsOptions configures the Instrument middleware.
+type MetricsOptions struct {
+	// Metrics records the metrics of requests.
+	Metrics Metrics
+
+	// Route returns the route label of a request. Defaults to RequestRoute.
+	Route func(req *http.Request) string
+}
+
+// Instrument creates a middleware that records the number, duration and body
+// sizes of requests, and the number of requests in flight. The sizes of
+// bodies with unknown lengths are counted as they are read.
+func Instrument(opts MetricsOptions) func(*Context) {
+	if opts.Route == nil {
+		opts.Route = RequestRoute
+	}
+
+	return func(ctx *Context) {
+		req := ctx.Request
+		labels := MetricLabels{
+			Method: req.Method,
+			Host:   req.URL.Host,
+			Route:  opts.Route(req),
+		}
+
+		var counter *countingBody
+		if req.Body != nil && req.Body != http.NoBody && req.ContentLength <= 0 {
+			counter = &countingBody{ReadCloser: req.Body}
+			ctx.Request = req.WithContext(req.Context())
+			ctx.Request.Body = counter
+		}
+
+		opts.Metrics.RequestStarted(labels)
+		errc := len(ctx.Errors)
+		start := time.Now()
+		ctx.Next()
+		duration := time.Since(start)
+		ctx.Request = req
+
+		reqSize := max(0, req.ContentLength)
+		if counter != nil {
+			reqSize = counter.count()
+		}
+
+		res := ctx.Response
+		labels.StatusClass = "error"
+		if res != nil && len(ctx.Errors) == errc {
+			labels.StatusClass = strconv.Itoa(res.StatusCode/100) + "xx"
+		}
+		opts.Metrics.RequestFinished(labels, duration, reqSize)
+
+		if res == nil || res.Body == nil || res.Body == http.NoBody {
+			opts.Metrics.ResponseRead(labels, 0)
+			return
+		}
+		res.Body = &countingBody{
+			ReadCloser: res.Body,
+			done: func(n int64) {
+				opts.Metrics.ResponseRead(labels, n)
+			},
+		}
+	}
+}
+
+// countingBody is a body that counts the bytes read from it, and reports the
+// count once when it is read to the end or closed.
+type countingBody struct {
+	io.ReadCloser
+	mtx  sync.Mutex
+	n    int64
+	done func(int64)
+	once sync.Once
+}
+
+// Read reads from the body and counts the bytes.
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mtx.Lock()
+	b.n += int64(n)
+	b.mtx.Unlock()
+
+	if err == io.EOF {
+		b.report()
+	}
+	return n, err
+}
+
+// Close closes the body and reports the count.
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.report()
+	return err
+}
+
+// count returns the number of bytes read.
+func (b *countingBody) count() int64 {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.n
+}
+
+// report calls the done function once with the number of bytes read.
+func (b *countingBody) report() {
+	if b.done != nil {
+		b.once.Do(func() { b.done(b.count()) })
+	}
+}
@@ -0,0 +1,127 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockMetrics records the calls of the Instrument middleware.
+type mockMetrics struct {
+	mtx          sync.Mutex
+	Started      []MetricLabels
+	Finished     []MetricLabels
+	RequestSize  int64
+	ResponseSize int64
+	Reads        int
+}
+
+func (m *mockMetrics) RequestStarted(labels MetricLabels) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.Started = append(m.Started, labels)
+}
+
+func (m *mockMetrics) RequestFinished(labels MetricLabels, d time.Duration, size int64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.Finished = append(m.Finished, labels)
+	m.RequestSize = size
+}
+
+func (m *mockMetrics) ResponseRead(labels MetricLabels, size int64) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.ResponseSize = size
+	m.Reads++
+}
+
+// TestInstrument tests recording the metrics of requests.
+func TestInstrument(t *testing.T) {
+	tests := []struct {
+		Name         string
+		Requester    *mockRequester
+		Builder      func(cl *Client) *RequestBuilder
+		Labels       MetricLabels
+		RequestSize  int64
+		ResponseSize int64
+	}{
+		{
+			Name:      "Request from builder",
+			Requester: &mockRequester{StatusCode: 201, Body: []byte(`{"id":1}`)},
+			Builder: func(cl *Client) *RequestBuilder {
+				return cl.NewRequest(
+					http.MethodPost, "http://localhost:8080/users/{}/devices",
+				).WithPathParameters("1").WithRawBody([]byte(`{"name":"phone"}`))
+			},
+			Labels: MetricLabels{
+				Method:      http.MethodPost,
+				Host:        "localhost:8080",
+				Route:       "/users/{}/devices",
+				StatusClass: "2xx",
+			},
+			RequestSize:  16,
+			ResponseSize: 8,
+		},
+		{
+			Name:      "Streamed request body",
+			Requester: &mockRequester{StatusCode: 404},
+			Builder: func(cl *Client) *RequestBuilder {
+				return cl.NewRequest(
+					http.MethodPut, "http://localhost:8080/files",
+				).WithBodyReader(io.MultiReader(strings.NewReader("gent")))
+			},
+			Labels: MetricLabels{
+				Method:      http.MethodPut,
+				Host:        "localhost:8080",
+				Route:       "/files",
+				StatusClass: "4xx",
+			},
+			RequestSize:  4,
+			ResponseSize: 0,
+		},
+		{
+			Name:      "Failed request",
+			Requester: &mockRequester{RequestErr: errors.New("failed")},
+			Builder: func(cl *Client) *RequestBuilder {
+				return cl.NewRequest(http.MethodGet, "http://localhost:8080/users")
+			},
+			Labels: MetricLabels{
+				Method:      http.MethodGet,
+				Host:        "localhost:8080",
+				Route:       "/users",
+				StatusClass: "error",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			metrics := &mockMetrics{}
+			cl := NewClient(test.Requester)
+			cl.Use(Instrument(MetricsOptions{Metrics: metrics}))
+
+			res, _ := test.Builder(cl).Send(context.Background())
+			if res != nil {
+				io.ReadAll(res.Body)
+				res.Body.Close()
+			}
+
+			started := test.Labels
+			started.StatusClass = ""
+
+			assert.Equal(t, []MetricLabels{started}, metrics.Started)
+			assert.Equal(t, []MetricLabels{test.Labels}, metrics.Finished)
+			assert.Equal(t, test.RequestSize, metrics.RequestSize)
+			assert.Equal(t, test.ResponseSize, metrics.ResponseSize)
+			assert.Equal(t, 1, metrics.Reads)
+		})
+	}
+}
@@ -0,0 +1,37 @@
+// Package msgpackgent provides gent marshalers for MessagePack bodies.
+package msgpackgent
+
+import (
+	"github.com/Soreing/gent"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// ContentType is the media type of MessagePack bodies.
+const ContentType = "application/msgpack"
+
+// Codec is the marshaler and unmarshaler of the package, which can be
+// registered in a gent.Registry with Register under ContentType.
+var Codec = gent.Codec{
+	Marshaler:   Marshaler,
+	Unmarshaler: Unmarshaler,
+}
+
+// Marshaler uses the msgpack marshaler to return the MessagePack encoded
+// body, with Content-Type and Accept application/msgpack headers.
+func Marshaler(body any) (dat []byte, hdrs map[string][]string, err error) {
+	if dat, err = msgpack.Marshal(body); err != nil {
+		return nil, nil, err
+	}
+
+	hdrs = map[string][]string{
+		"Content-Type": {ContentType},
+		"Accept":       {ContentType},
+	}
+	return dat, hdrs, nil
+}
+
+// Unmarshaler uses the msgpack unmarshaler to decode the MessagePack encoded
+// body into the object.
+func Unmarshaler(data []byte, v any) error {
+	return msgpack.Unmarshal(data, v)
+}
@@ -0,0 +1,86 @@
+package msgpackgent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Id   int    `msgpack:"id"`
+	Name string `msgpack:"name"`
+}
+
+// TestMarshalers tests marshaling and unmarshaling objects with their
+// MessagePack encoding.
+func TestMarshalers(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Object  user
+		Bytes   []byte
+		Headers map[string][]string
+	}{
+		{
+			Name:   "Marshal object",
+			Object: user{Id: 123, Name: "John Smith"},
+			Bytes: []byte{
+				// fixmap of 2 pairs
+				0x82,
+				// fixstr of 2 bytes
+				0xa2, 'i', 'd',
+				// positive fixint 123
+				0x7b,
+				// fixstr of 4 bytes
+				0xa4, 'n', 'a', 'm', 'e',
+				// fixstr of 10 bytes
+				0xaa, 'J', 'o', 'h', 'n', ' ', 'S', 'm', 'i', 't', 'h',
+			},
+			Headers: map[string][]string{
+				"Content-Type": {"application/msgpack"},
+				"Accept":       {"application/msgpack"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			dat, hdrs, err := Marshaler(test.Object)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Bytes, dat)
+			assert.Equal(t, test.Headers, hdrs)
+
+			var obj user
+			err = Unmarshaler(dat, &obj)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Object, obj)
+		})
+	}
+}
+
+// TestUnmarshalerInvalid tests unmarshaling invalid bodies.
+func TestUnmarshalerInvalid(t *testing.T) {
+	tests := []struct {
+		Name  string
+		Bytes []byte
+	}{
+		{
+			Name:  "Truncated body",
+			Bytes: []byte{0x82},
+		},
+		{
+			Name:  "Never used type",
+			Bytes: []byte{0xc1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var obj user
+			err := Unmarshaler(test.Bytes, &obj)
+
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package gent
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// quoteEscaper escapes quotes and backslashes in multipart header values.
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// MultipartForm describes the text fields and file parts of a
+// multipart/form-data request body. Use it with [MultipartMarshaler] to
+// buffer the body, or [MultipartStreamMarshaler] to stream large files.
+type MultipartForm struct {
+	boundary string
+	parts    []multipartPart
+}
+
+// multipartPart is a text field or file part of a multipart form.
+type multipartPart struct {
+	field       string
+	value       string
+	filename    string
+	contentType string
+	file        bool
+	open        func() (io.Reader, func() error, error)
+}
+
+// NewMultipartForm creates an empty multipart form with a random boundary.
+func NewMultipartForm() *MultipartForm {
+	return &MultipartForm{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+}
+
+// WithField adds a text field to the form.
+func (mf *MultipartForm) WithField(
+	field string,
+	value string,
+) *MultipartForm {
+	mf.parts = append(mf.parts, multipartPart{field: field, value: value})
+	return mf
+}
+
+// WithFile adds a file part to the form with the content of the reader. If the
+// content type is empty, it defaults to application/octet-stream. Readers
+// that implement io.Seeker are rewound to their current offset every time the
+// form is written, otherwise the form can only be written once and writing it
+// again fails with ErrBodyNotRewindable.
+func (mf *MultipartForm) WithFile(
+	field string,
+	filename string,
+	contentType string,
+	r io.Reader,
+) *MultipartForm {
+	used := atomic.Bool{}
+	open := func() (io.Reader, func() error, error) {
+		if used.Swap(true) {
+			return nil, nil, ErrBodyNotRewindable
+		}
+		return r, nil, nil
+	}
+	if rs, ok := r.(io.Seeker); ok {
+		if offset, err := rs.Seek(0, io.SeekCurrent); err == nil {
+			open = func() (io.Reader, func() error, error) {
+				_, err := rs.Seek(offset, io.SeekStart)
+				return r, nil, err
+			}
+		}
+	}
+
+	mf.parts = append(mf.parts, multipartPart{
+		field:       field,
+		filename:    filename,
+		contentType: contentType,
+		file:        true,
+		open:        open,
+	})
+	return mf
+}
+
+// WithFileFromDisk adds a file part to the form with the content of a file
+// on disk, named after the base of its path. The file is opened and closed
+// every time the form is written. If the content type is empty, it defaults
+// to application/octet-stream.
+func (mf *MultipartForm) WithFileFromDisk(
+	field string,
+	path string,
+	contentType string,
+) *MultipartForm {
+	mf.parts = append(mf.parts, multipartPart{
+		field:       field,
+		filename:    filepath.Base(path),
+		contentType: contentType,
+		file:        true,
+		open: func() (io.Reader, func() error, error) {
+			f, err := os.Open(path)
+			if err != nil {
+				return nil, nil, err
+			}
+			return f, f.Close, nil
+		},
+	})
+	return mf
+}
+
+// ContentType returns the multipart/form-data content type with the boundary
+// of the form.
+func (mf *MultipartForm) ContentType() string {
+	return "multipart/form-data; boundary=" + mf.boundary
+}
+
+// WriteTo writes the encoded form to the writer.
+func (mf *MultipartForm) WriteTo(w io.Writer) (n int64, err error) {
+	cw := &countWriter{w: w}
+	mw := multipart.NewWriter(cw)
+	if err := mw.SetBoundary(mf.boundary); err != nil {
+		return cw.n, err
+	}
+
+	for _, part := range mf.parts {
+		if !part.file {
+			if err := mw.WriteField(part.field, part.value); err != nil {
+				return cw.n, err
+			}
+		} else if err := part.write(mw); err != nil {
+			return cw.n, err
+		}
+	}
+
+	err = mw.Close()
+	return cw.n, err
+}
+
+// write writes a file part into the multipart writer.
+func (part *multipartPart) write(mw *multipart.Writer) error {
+	ctype := part.contentType
+	if ctype == "" {
+		ctype = "application/octet-stream"
+	}
+
+	hdr := textproto.MIMEHeader{}
+	hdr.Set("Content-Disposition", fmt.Sprintf(
+		`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(part.field), quoteEscaper.Replace(part.filename),
+	))
+	hdr.Set("Content-Type", ctype)
+
+	pw, err := mw.CreatePart(hdr)
+	if err != nil {
+		return err
+	}
+
+	r, closer, err := part.open()
+	if err != nil {
+		return err
+	}
+	if closer != nil {
+		defer closer()
+	}
+
+	_, err = io.Copy(pw, r)
+	return err
+}
+
+// countWriter counts the bytes written to the underlying writer.
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write writes to the underlying writer and counts the bytes written.
+func (cw *countWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+// MultipartMarshaler encodes a *MultipartForm into a byte array and returns a
+// Content-Type multipart/form-data header with the boundary of the form.
+func MultipartMarshaler(body any) (dat []byte, hdrs map[string][]string, err error) {
+	form, ok := body.(*MultipartForm)
+	if !ok || form == nil {
+		return nil, nil, ErrInvalidBodyType
+	}
+
+	buf := bytes.Buffer{}
+	if _, err = form.WriteTo(&buf); err != nil {
+		return nil, nil, err
+	}
+
+	hdrs = map[string][]string{"Content-Type": {form.ContentType()}}
+	return buf.Bytes(), hdrs, nil
+}
+
+// MultipartStreamMarshaler streams a *MultipartForm into the request body
+// without buffering its files, and returns a Content-Type multipart/form-data
+// header with the boundary of the form.
+func MultipartStreamMarshaler(body any) (enc func(io.Writer) error, hdrs map[string][]string, err error) {
+	form, ok := body.(*MultipartForm)
+	if !ok || form == nil {
+		return nil, nil, ErrInvalidBodyType
+	}
+
+	hdrs = map[string][]string{"Content-Type": {form.ContentType()}}
+	enc = func(w io.Writer) error {
+		_, err := form.WriteTo(w)
+		return err
+	}
+	return enc, hdrs, nil
+}
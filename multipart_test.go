@@ -0,0 +1,235 @@
+package gent
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// multipartResult is a part read back from an encoded multipart form.
+type multipartResult struct {
+	Field       string
+	Filename    string
+	ContentType string
+	Content     string
+}
+
+// readMultipart reads back the parts of an encoded multipart form.
+func readMultipart(t *testing.T, ctype string, r io.Reader) []multipartResult {
+	_, params, err := mime.ParseMediaType(ctype)
+	assert.Nil(t, err)
+
+	var res []multipartResult
+	mr := multipart.NewReader(r, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return res
+		} else if !assert.Nil(t, err) {
+			return nil
+		}
+
+		content, _ := io.ReadAll(part)
+		res = append(res, multipartResult{
+			Field:       part.FormName(),
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Content:     string(content),
+		})
+	}
+}
+
+// TestMultipartMarshalers tests encoding multipart forms.
+func TestMultipartMarshalers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "avatar.png")
+	os.WriteFile(path, []byte("PNG"), 0o600)
+
+	tests := []struct {
+		Name   string
+		Form   func() *MultipartForm
+		Result []multipartResult
+	}{
+		{
+			Name: "Text fields",
+			Form: func() *MultipartForm {
+				return NewMultipartForm().
+					WithField("name", "John Smith").
+					WithField("email", "john@example.com")
+			},
+			Result: []multipartResult{
+				{Field: "name", Content: "John Smith"},
+				{Field: "email", Content: "john@example.com"},
+			},
+		},
+		{
+			Name: "File from reader",
+			Form: func() *MultipartForm {
+				return NewMultipartForm().
+					WithField("name", "John Smith").
+					WithFile("cv", "cv.pdf", "application/pdf", strings.NewReader("PDF"))
+			},
+			Result: []multipartResult{
+				{Field: "name", Content: "John Smith"},
+				{Field: "cv", Filename: "cv.pdf", ContentType: "application/pdf", Content: "PDF"},
+			},
+		},
+		{
+			Name: "File from disk",
+			Form: func() *MultipartForm {
+				return NewMultipartForm().WithFileFromDisk("avatar", path, "")
+			},
+			Result: []multipartResult{
+				{
+					Field:       "avatar",
+					Filename:    "avatar.png",
+					ContentType: "application/octet-stream",
+					Content:     "PNG",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name+" buffered", func(t *testing.T) {
+			form := test.Form()
+			dat, hdrs, err := MultipartMarshaler(form)
+
+			assert.Nil(t, err)
+			assert.Equal(t, []string{form.ContentType()}, hdrs["Content-Type"])
+			res := readMultipart(t, form.ContentType(), strings.NewReader(string(dat)))
+			assert.Equal(t, test.Result, res)
+		})
+
+		t.Run(test.Name+" streamed", func(t *testing.T) {
+			form := test.Form()
+			req, err := NewRequest(
+				http.MethodPost, "http://localhost:8080/uploads",
+			).WithBodyStream(
+				form, MultipartStreamMarshaler,
+			).Build(context.Background())
+
+			assert.Nil(t, err)
+			ctype := req.Header.Get("Content-Type")
+			assert.Equal(t, form.ContentType(), ctype)
+			res := readMultipart(t, ctype, req.Body)
+			assert.Equal(t, test.Result, res)
+
+			body, _ := req.GetBody()
+			res = readMultipart(t, ctype, body)
+			assert.Equal(t, test.Result, res)
+		})
+	}
+}
+
+// TestMultipartMarshalersInvalidBody tests encoding objects that are not
+// multipart forms.
+func TestMultipartMarshalersInvalidBody(t *testing.T) {
+	tests := []struct {
+		Name string
+		Body any
+	}{
+		{Name: "Map body", Body: map[string]string{"name": "John Smith"}},
+		{Name: "Nil form", Body: (*MultipartForm)(nil)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, _, err := MultipartMarshaler(test.Body)
+			assert.Equal(t, ErrInvalidBodyType, err)
+
+			_, _, err = MultipartStreamMarshaler(test.Body)
+			assert.Equal(t, ErrInvalidBodyType, err)
+		})
+	}
+}
+
+// TestMultipartFormMissingFile tests writing forms with missing files.
+func TestMultipartFormMissingFile(t *testing.T) {
+	tests := []struct {
+		Name string
+		Path string
+	}{
+		{Name: "Missing file", Path: filepath.Join(t.TempDir(), "missing.txt")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			form := NewMultipartForm().WithFileFromDisk("file", test.Path, "")
+
+			_, _, err := MultipartMarshaler(form)
+
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+// TestMultipartFormReplay tests writing forms with file readers again.
+func TestMultipartFormReplay(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Reader func() io.Reader
+		Result []multipartResult
+		Error  error
+	}{
+		{
+			Name:   "Seekable reader",
+			Reader: func() io.Reader { return strings.NewReader("PDF") },
+			Result: []multipartResult{
+				{Field: "cv", Filename: "cv.pdf", ContentType: "application/pdf", Content: "PDF"},
+			},
+		},
+		{
+			Name:   "Non seekable reader",
+			Reader: func() io.Reader { return io.MultiReader(strings.NewReader("PDF")) },
+			Error:  ErrBodyNotRewindable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name+" buffered", func(t *testing.T) {
+			form := NewMultipartForm().
+				WithFile("cv", "cv.pdf", "application/pdf", test.Reader())
+			_, _, err := MultipartMarshaler(form)
+			assert.Nil(t, err)
+
+			dat, _, err := MultipartMarshaler(form)
+
+			assert.ErrorIs(t, err, test.Error)
+			if test.Error == nil {
+				res := readMultipart(t, form.ContentType(), strings.NewReader(string(dat)))
+				assert.Equal(t, test.Result, res)
+			}
+		})
+
+		t.Run(test.Name+" streamed", func(t *testing.T) {
+			form := NewMultipartForm().
+				WithFile("cv", "cv.pdf", "application/pdf", test.Reader())
+			req, err := NewRequest(
+				http.MethodPost, "http://localhost:8080/uploads",
+			).WithBodyStream(
+				form, MultipartStreamMarshaler,
+			).Build(context.Background())
+			assert.Nil(t, err)
+			_, err = io.ReadAll(req.Body)
+			assert.Nil(t, err)
+
+			body, err := req.GetBody()
+			assert.Nil(t, err)
+			dat, err := io.ReadAll(body)
+
+			assert.ErrorIs(t, err, test.Error)
+			if test.Error == nil {
+				res := readMultipart(t, form.ContentType(), strings.NewReader(string(dat)))
+				assert.Equal(t, test.Result, res)
+			}
+		})
+	}
+}
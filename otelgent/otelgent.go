@@ -0,0 +1,157 @@
+// Package otelgent provides an OpenTelemetry tracing middleware for gent.
+package otelgent
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/Soreing/gent"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name of the tracer.
+const ScopeName = "github.com/Soreing/gent/otelgent"
+
+// Options configures the tracing middleware.
+type Options struct {
+	// TracerProvider creates the tracer of the middleware. Defaults to the
+	// global tracer provider.
+	TracerProvider trace.TracerProvider
+
+	// Propagator injects the span context and baggage into request headers.
+	// Defaults to the W3C Trace Context and Baggage propagators.
+	Propagator propagation.TextMapPropagator
+
+	// SpanName returns the name of the span of a request. Defaults to the
+	// method and the route of the request's format, or only the method.
+	SpanName func(req *http.Request) string
+}
+
+// Middleware creates a middleware that starts a client span for each request,
+// injects the span context and baggage into the request headers, and records
+// HTTP semantic convention attributes of the request and response. Spans
+// become errors if middlewares add errors to the context or the response has a
+// 4xx or 5xx status code. When the middleware runs more than once for the same
+// request, such as after a Retry middleware, each run is a separate span with
+// the number of resends. Using the middleware before and after a Retry
+// middleware records the attempts as child spans of the request's span.
+func Middleware(opts Options) func(*gent.Context) {
+	if opts.TracerProvider == nil {
+		opts.TracerProvider = otel.GetTracerProvider()
+	}
+	if opts.Propagator == nil {
+		opts.Propagator = propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		)
+	}
+	if opts.SpanName == nil {
+		opts.SpanName = spanName
+	}
+
+	tracer := opts.TracerProvider.Tracer(
+		ScopeName,
+		trace.WithSchemaURL(semconv.SchemaURL),
+	)
+	attemptsKey := gent.NewKey[int]("otelgent.attempts")
+
+	return func(ctx *gent.Context) {
+		req := ctx.Request
+
+		attempts, _ := attemptsKey.Get(ctx)
+		attemptsKey.Set(ctx, attempts+1)
+
+		attrs := requestAttributes(req)
+		if attempts > 0 {
+			attrs = append(attrs, semconv.HTTPRequestResendCount(attempts))
+		}
+
+		sctx, span := tracer.Start(
+			req.Context(),
+			opts.SpanName(req),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		sreq := req.Clone(sctx)
+		opts.Propagator.Inject(sctx, propagation.HeaderCarrier(sreq.Header))
+
+		errc := len(ctx.Errors)
+		ctx.Request = sreq
+		ctx.Next()
+		ctx.Request = req
+
+		if res := ctx.Response; res != nil {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+			if res.StatusCode >= 400 {
+				span.SetAttributes(semconv.ErrorTypeKey.String(strconv.Itoa(res.StatusCode)))
+				span.SetStatus(codes.Error, "")
+			}
+		}
+
+		if errc < len(ctx.Errors) {
+			for _, err := range ctx.Errors[errc:] {
+				span.RecordError(err)
+			}
+			err := ctx.Errors[len(ctx.Errors)-1]
+			span.SetAttributes(semconv.ErrorType(err))
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+}
+
+// spanName returns the method and the route of the request's format, or only
+// the method if the request was not built by a RequestBuilder.
+func spanName(req *http.Request) string {
+	if route := gent.RequestRoute(req); route != "" {
+		return req.Method + " " + route
+	}
+	return req.Method
+}
+
+// requestAttributes returns the semantic convention attributes of a request.
+func requestAttributes(req *http.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.HTTPRequestMethodKey.String(req.Method),
+		semconv.URLFull(redactedURL(req)),
+		semconv.URLScheme(req.URL.Scheme),
+	}
+
+	host, port := req.URL.Host, ""
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		host, port = h, p
+	}
+	attrs = append(attrs, semconv.ServerAddress(host))
+	if n, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, semconv.ServerPort(n))
+	} else if req.URL.Scheme == "https" {
+		attrs = append(attrs, semconv.ServerPort(443))
+	} else if req.URL.Scheme == "http" {
+		attrs = append(attrs, semconv.ServerPort(80))
+	}
+
+	if route := gent.RequestRoute(req); route != "" {
+		attrs = append(attrs, semconv.URLTemplate(route))
+	}
+	if ua := req.UserAgent(); ua != "" {
+		attrs = append(attrs, semconv.UserAgentOriginal(ua))
+	}
+	return attrs
+}
+
+// redactedURL returns the URL of the request without user credentials.
+func redactedURL(req *http.Request) string {
+	if req.URL.User == nil {
+		return req.URL.String()
+	}
+	u := *req.URL
+	u.User = nil
+	return u.String()
+}
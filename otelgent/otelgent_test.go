@@ -0,0 +1,162 @@
+package otelgent
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Soreing/gent"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// mockRequester returns responses with a status code or an error.
+type mockRequester struct {
+	StatusCode  int
+	RequestErr  error
+	LastRequest *http.Request
+}
+
+func (m *mockRequester) Do(r *http.Request) (*http.Response, error) {
+	m.LastRequest = r
+	if m.RequestErr != nil {
+		return nil, m.RequestErr
+	}
+	res := httptest.NewRecorder().Result()
+	res.StatusCode = m.StatusCode
+	return res, nil
+}
+
+func (m *mockRequester) CloseIdleConnections() {}
+
+// attrs returns the attributes of a span as a map.
+func attrs(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	m := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes() {
+		m[kv.Key] = kv.Value
+	}
+	return m
+}
+
+// TestMiddleware tests the spans of requests.
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Requester  *mockRequester
+		SpanName   string
+		StatusCode int64
+		Status     codes.Code
+		ErrorType  string
+		Events     int
+	}{
+		{
+			Name:       "Successful request",
+			Requester:  &mockRequester{StatusCode: 200},
+			SpanName:   "GET /users/{}",
+			StatusCode: 200,
+			Status:     codes.Unset,
+		},
+		{
+			Name:       "Error status code",
+			Requester:  &mockRequester{StatusCode: 503},
+			SpanName:   "GET /users/{}",
+			StatusCode: 503,
+			Status:     codes.Error,
+			ErrorType:  "503",
+		},
+		{
+			Name:      "Request error",
+			Requester: &mockRequester{RequestErr: errors.New("connection refused")},
+			SpanName:  "GET /users/{}",
+			Status:    codes.Error,
+			ErrorType: "*errors.errorString",
+			Events:    1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			rec := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+			cl := gent.NewClient(test.Requester)
+			cl.Use(Middleware(Options{TracerProvider: tp}))
+
+			member, _ := baggage.NewMember("tenant", "acme")
+			bag, _ := baggage.New(member)
+			ctx := baggage.ContextWithBaggage(context.Background(), bag)
+
+			cl.NewRequest(
+				http.MethodGet, "http://localhost:8080/users/{}",
+			).WithPathParameters(
+				"1",
+			).Send(ctx)
+
+			spans := rec.Ended()
+			assert.Len(t, spans, 1)
+			span := spans[0]
+			a := attrs(span)
+
+			assert.Equal(t, test.SpanName, span.Name())
+			assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+			assert.Equal(t, test.Status, span.Status().Code)
+			assert.Equal(t, "GET", a["http.request.method"].AsString())
+			assert.Equal(t, "http://localhost:8080/users/1", a["url.full"].AsString())
+			assert.Equal(t, "/users/{}", a["url.template"].AsString())
+			assert.Equal(t, "localhost", a["server.address"].AsString())
+			assert.Equal(t, int64(8080), a["server.port"].AsInt64())
+			assert.Equal(t, test.StatusCode, a["http.response.status_code"].AsInt64())
+			assert.Equal(t, test.ErrorType, a["error.type"].AsString())
+			assert.Len(t, span.Events(), test.Events)
+
+			hdr := test.Requester.LastRequest.Header
+			assert.Equal(t,
+				"00-"+span.SpanContext().TraceID().String()+"-"+span.SpanContext().SpanID().String()+"-01",
+				hdr.Get("Traceparent"),
+			)
+			assert.Equal(t, "tenant=acme", hdr.Get("Baggage"))
+		})
+	}
+}
+
+// TestMiddlewareRetries tests that attempts of retried requests are child
+// spans of the request's span, without using the values of the context.
+func TestMiddlewareRetries(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+
+	cl := gent.NewClient(&mockRequester{StatusCode: 503})
+	cl.Use(Middleware(Options{TracerProvider: tp}))
+	cl.Use(gent.Retry(gent.RetryOptions{MaxAttempts: 3, BaseDelay: 1}))
+	cl.Use(Middleware(Options{TracerProvider: tp}))
+
+	var values map[string]any
+	cl.Use(func(ctx *gent.Context) {
+		values = ctx.Values
+		ctx.Next()
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080/users", nil)
+	cl.Do(req)
+
+	assert.Empty(t, values)
+	spans := rec.Ended()
+	assert.Len(t, spans, 4)
+
+	parent := spans[3]
+	assert.Equal(t, "GET", parent.Name())
+	assert.False(t, parent.Parent().IsValid())
+
+	for i, span := range spans[:3] {
+		a := attrs(span)
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, int64(i), a["http.request.resend_count"].AsInt64())
+	}
+}
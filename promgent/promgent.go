@@ -0,0 +1,134 @@
+// Package promgent provides a Prometheus implementation of gent.Metrics.
+package promgent
+
+import (
+	"time"
+
+	"github.com/Soreing/gent"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Options configures the Prometheus metrics.
+type Options struct {
+	// Namespace and Subsystem prefix the names of the metrics. They default to
+	// "gent" and "client".
+	Namespace string
+	Subsystem string
+
+	// DurationBuckets are the buckets of the request duration histogram in
+	// seconds. Defaults to prometheus.DefBuckets.
+	DurationBuckets []float64
+
+	// SizeBuckets are the buckets of the body size histograms in bytes.
+	// Defaults to exponential buckets from 64 bytes to 16 MiB.
+	SizeBuckets []float64
+
+	// Registerer registers the metrics. Defaults to the default registerer.
+	Registerer prometheus.Registerer
+}
+
+// Metrics records gent metrics into Prometheus collectors.
+type Metrics struct {
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	inFlight     *prometheus.GaugeVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+var _ gent.Metrics = (*Metrics)(nil)
+
+// NewMetrics creates Prometheus metrics and registers their collectors.
+func NewMetrics(opts Options) (*Metrics, error) {
+	if opts.Namespace == "" {
+		opts.Namespace = "gent"
+	}
+	if opts.Subsystem == "" {
+		opts.Subsystem = "client"
+	}
+	if opts.DurationBuckets == nil {
+		opts.DurationBuckets = prometheus.DefBuckets
+	}
+	if opts.SizeBuckets == nil {
+		opts.SizeBuckets = prometheus.ExponentialBuckets(64, 4, 10)
+	}
+	if opts.Registerer == nil {
+		opts.Registerer = prometheus.DefaultRegisterer
+	}
+
+	labels := []string{"method", "host", "route", "status_class"}
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "requests_total",
+			Help:      "Number of completed requests.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "request_duration_seconds",
+			Help:      "Duration of requests until the response headers are received.",
+			Buckets:   opts.DurationBuckets,
+		}, labels),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "requests_in_flight",
+			Help:      "Number of requests in flight.",
+		}, labels[:3]),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "request_size_bytes",
+			Help:      "Size of request bodies.",
+			Buckets:   opts.SizeBuckets,
+		}, labels),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opts.Namespace,
+			Subsystem: opts.Subsystem,
+			Name:      "response_size_bytes",
+			Help:      "Size of response bodies.",
+			Buckets:   opts.SizeBuckets,
+		}, labels),
+	}
+
+	for _, c := range []prometheus.Collector{
+		m.requests, m.duration, m.inFlight, m.requestSize, m.responseSize,
+	} {
+		if err := opts.Registerer.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// RequestStarted increments the number of requests in flight.
+func (m *Metrics) RequestStarted(labels gent.MetricLabels) {
+	m.inFlight.WithLabelValues(labels.Method, labels.Host, labels.Route).Inc()
+}
+
+// RequestFinished decrements the number of requests in flight, and records
+// the request, its duration and the size of its body.
+func (m *Metrics) RequestFinished(
+	labels gent.MetricLabels,
+	duration time.Duration,
+	requestSize int64,
+) {
+	m.inFlight.WithLabelValues(labels.Method, labels.Host, labels.Route).Dec()
+
+	lvs := values(labels)
+	m.requests.WithLabelValues(lvs...).Inc()
+	m.duration.WithLabelValues(lvs...).Observe(duration.Seconds())
+	m.requestSize.WithLabelValues(lvs...).Observe(float64(requestSize))
+}
+
+// ResponseRead records the size of a response body.
+func (m *Metrics) ResponseRead(labels gent.MetricLabels, responseSize int64) {
+	m.responseSize.WithLabelValues(values(labels)...).Observe(float64(responseSize))
+}
+
+// values returns the label values of the labels.
+func values(labels gent.MetricLabels) []string {
+	return []string{labels.Method, labels.Host, labels.Route, labels.StatusClass}
+}
@@ -0,0 +1,58 @@
+package promgent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Soreing/gent"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMetrics tests recording requests into Prometheus collectors.
+func TestMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":1}`))
+		},
+	))
+	defer srv.Close()
+
+	reg := prometheus.NewRegistry()
+	metrics, err := NewMetrics(Options{Registerer: reg})
+	assert.Nil(t, err)
+
+	cl := gent.NewClient(srv.Client())
+	cl.Use(gent.Instrument(gent.MetricsOptions{Metrics: metrics}))
+
+	for _, id := range []string{"1", "2"} {
+		res, err := cl.NewRequest(
+			http.MethodPost, srv.URL+"/users/{}/devices",
+		).WithPathParameters(
+			id,
+		).WithRawBody(
+			[]byte(`{"name":"phone"}`),
+		).Send(context.Background())
+		assert.Nil(t, err)
+
+		io.ReadAll(res.Body)
+		res.Body.Close()
+	}
+
+	host := srv.Listener.Addr().String()
+	lvs := []string{http.MethodPost, host, "/users/{}/devices", "2xx"}
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(metrics.requests.WithLabelValues(lvs...)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(metrics.inFlight.WithLabelValues(lvs[:3]...)))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.duration))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.requestSize))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.responseSize))
+
+	_, err = NewMetrics(Options{Registerer: reg})
+	assert.NotNil(t, err)
+}
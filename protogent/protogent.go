@@ -0,0 +1,48 @@
+// Package protogent provides gent marshalers for protocol buffer bodies.
+package protogent
+
+import (
+	"github.com/Soreing/gent"
+	"google.golang.org/protobuf/proto"
+)
+
+// ContentType is the media type of protocol buffer bodies.
+const ContentType = "application/x-protobuf"
+
+// Codec is the marshaler and unmarshaler of the package, which can be
+// registered in a gent.Registry with Register under ContentType.
+var Codec = gent.Codec{
+	Marshaler:   Marshaler,
+	Unmarshaler: Unmarshaler,
+}
+
+// Marshaler uses the protobuf marshaler to return the encoded proto.Message
+// body, with Content-Type and Accept application/x-protobuf headers. It
+// returns gent.ErrInvalidBodyType if the body is not a proto.Message.
+func Marshaler(body any) (dat []byte, hdrs map[string][]string, err error) {
+	msg, ok := body.(proto.Message)
+	if !ok {
+		return nil, nil, gent.ErrInvalidBodyType
+	}
+
+	if dat, err = proto.Marshal(msg); err != nil {
+		return nil, nil, err
+	}
+
+	hdrs = map[string][]string{
+		"Content-Type": {ContentType},
+		"Accept":       {ContentType},
+	}
+	return dat, hdrs, nil
+}
+
+// Unmarshaler uses the protobuf unmarshaler to decode the body into a
+// proto.Message. It returns gent.ErrInvalidBodyType if the object is not
+// a proto.Message.
+func Unmarshaler(data []byte, v any) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return gent.ErrInvalidBodyType
+	}
+	return proto.Unmarshal(data, msg)
+}
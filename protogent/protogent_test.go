@@ -0,0 +1,84 @@
+package protogent
+
+import (
+	"testing"
+
+	"github.com/Soreing/gent"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// TestMarshaler tests marshaling protocol buffer messages.
+func TestMarshaler(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Object  any
+		Headers map[string][]string
+		Error   error
+	}{
+		{
+			Name:   "Marshal message",
+			Object: wrapperspb.String("John Smith"),
+			Headers: map[string][]string{
+				"Content-Type": {"application/x-protobuf"},
+				"Accept":       {"application/x-protobuf"},
+			},
+			Error: nil,
+		},
+		{
+			Name:   "Marshal invalid type",
+			Object: map[string]any{"name": "John Smith"},
+			Error:  gent.ErrInvalidBodyType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			dat, hdrs, err := Marshaler(test.Object)
+
+			assert.Equal(t, test.Error, err)
+			assert.Equal(t, test.Headers, hdrs)
+			if err == nil {
+				msg := &wrapperspb.StringValue{}
+				assert.Nil(t, proto.Unmarshal(dat, msg))
+				assert.True(t, proto.Equal(test.Object.(proto.Message), msg))
+			}
+		})
+	}
+}
+
+// TestUnmarshaler tests unmarshaling protocol buffer messages.
+func TestUnmarshaler(t *testing.T) {
+	data, _ := proto.Marshal(wrapperspb.String("John Smith"))
+
+	tests := []struct {
+		Name   string
+		Object any
+		Value  string
+		Error  error
+	}{
+		{
+			Name:   "Unmarshal message",
+			Object: &wrapperspb.StringValue{},
+			Value:  "John Smith",
+			Error:  nil,
+		},
+		{
+			Name:   "Unmarshal invalid type",
+			Object: &map[string]any{},
+			Error:  gent.ErrInvalidBodyType,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			err := Unmarshaler(data, test.Object)
+
+			assert.Equal(t, test.Error, err)
+			if err == nil {
+				assert.Equal(t, test.Value, test.Object.(*wrapperspb.StringValue).Value)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package gent
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned by Client.Do when the rate limiter would need to
+// wait beyond the deadline of the request's context for a token.
+var ErrRateLimited = errors.New("rate limit wait exceeds context deadline")
+
+// RateLimitOptions configures the behavior of the RateLimit middleware.
+type RateLimitOptions struct {
+	// Rate is the number of requests allowed per second. Defaults to 10.
+	Rate float64
+
+	// Burst is the maximum number of tokens in a bucket, which allows
+	// requests to be sent in bursts over the rate. Defaults to 1.
+	Burst int
+
+	// Leaky makes the limiter behave as a leaky bucket, where requests are
+	// sent at a constant rate without bursts, regardless of Burst.
+	Leaky bool
+
+	// Key optionally returns the key that identifies the bucket of a request.
+	// Defaults to RateLimitByHost.
+	Key func(*Context) string
+}
+
+// RateLimitByHost identifies rate limit buckets by the host of the request.
+func RateLimitByHost(ctx *Context) string {
+	return ctx.Request.URL.Host
+}
+
+// RateLimitByRoute identifies rate limit buckets by the host, method and path
+// template of the request, such as "GET localhost:8080/users/{}".
+func RateLimitByRoute(ctx *Context) string {
+	return ctx.Request.Method + " " + ctx.Request.URL.Host + pathTemplate(ctx.Request)
+}
+
+// bucket is a token bucket that refills at a constant rate.
+type bucket struct {
+	mtx     sync.Mutex
+	tokens  float64
+	last    time.Time
+	blocked time.Time
+}
+
+// RateLimit creates a middleware that limits the rate of requests with token
+// buckets, blocking until a token is available. If the wait would exceed the
+// deadline of the request's context, the request fails with ErrRateLimited.
+// Buckets adjust to the X-RateLimit-Remaining and X-RateLimit-Reset headers
+// of the responses.
+func RateLimit(opts RateLimitOptions) func(*Context) {
+	if opts.Rate <= 0 {
+		opts.Rate = 10
+	}
+	if opts.Burst <= 0 || opts.Leaky {
+		opts.Burst = 1
+	}
+	if opts.Key == nil {
+		opts.Key = RateLimitByHost
+	}
+
+	mtx := sync.Mutex{}
+	buckets := map[string]*bucket{}
+
+	return func(ctx *Context) {
+		key := opts.Key(ctx)
+		mtx.Lock()
+		bk, ok := buckets[key]
+		if !ok {
+			bk = &bucket{tokens: float64(opts.Burst), last: time.Now()}
+			buckets[key] = bk
+		}
+		mtx.Unlock()
+
+		delay := bk.reserve(&opts)
+		if dl, ok := ctx.Deadline(); ok && time.Until(dl) < delay {
+			bk.cancel()
+			ctx.AbortWithError(ErrRateLimited)
+			return
+		} else if delay > 0 && !wait(ctx, delay) {
+			bk.cancel()
+			ctx.AbortWithError(ctx.Err())
+			return
+		}
+
+		ctx.Next()
+
+		if ctx.Response != nil {
+			bk.adjust(ctx.Response.Header)
+		}
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the request
+// needs to wait before the token becomes available.
+func (bk *bucket) reserve(opts *RateLimitOptions) time.Duration {
+	bk.mtx.Lock()
+	defer bk.mtx.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(bk.last).Seconds()
+	bk.tokens = math.Min(float64(opts.Burst), bk.tokens+elapsed*opts.Rate)
+	bk.last = now
+	bk.tokens--
+
+	var delay time.Duration
+	if bk.tokens < 0 {
+		delay = time.Duration(-bk.tokens / opts.Rate * float64(time.Second))
+	}
+	if until := bk.blocked.Sub(now); until > delay {
+		delay = until
+	}
+	return delay
+}
+
+// cancel returns a reserved token to the bucket.
+func (bk *bucket) cancel() {
+	bk.mtx.Lock()
+	bk.tokens++
+	bk.mtx.Unlock()
+}
+
+// adjust updates the bucket from the X-RateLimit-Remaining and
+// X-RateLimit-Reset headers of a response. The reset value is either a
+// number of seconds or a unix timestamp.
+func (bk *bucket) adjust(hdr http.Header) {
+	remaining, err := strconv.Atoi(hdr.Get("X-RateLimit-Remaining"))
+	if err != nil || remaining < 0 {
+		return
+	}
+
+	bk.mtx.Lock()
+	defer bk.mtx.Unlock()
+
+	bk.tokens = math.Min(bk.tokens, float64(remaining))
+	if remaining > 0 {
+		return
+	}
+
+	reset, err := strconv.ParseFloat(hdr.Get("X-RateLimit-Reset"), 64)
+	if err != nil || reset < 0 {
+		return
+	}
+
+	// values over a billion seconds are treated as unix timestamps
+	if reset > 1e9 {
+		bk.blocked = time.Unix(0, int64(reset*float64(time.Second)))
+	} else {
+		bk.blocked = time.Now().Add(time.Duration(reset * float64(time.Second)))
+	}
+}
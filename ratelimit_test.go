@@ -0,0 +1,189 @@
+package gent
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRateLimit tests delaying requests with the rate limit middleware.
+func TestRateLimit(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Options  RateLimitOptions
+		Urls     []string
+		MinDelay time.Duration
+		MaxDelay time.Duration
+	}{
+		{
+			Name:     "Requests within burst",
+			Options:  RateLimitOptions{Rate: 10, Burst: 3},
+			Urls:     []string{"http://a", "http://a", "http://a"},
+			MinDelay: 0,
+			MaxDelay: 50 * time.Millisecond,
+		},
+		{
+			Name:     "Requests over burst",
+			Options:  RateLimitOptions{Rate: 20, Burst: 1},
+			Urls:     []string{"http://a", "http://a", "http://a"},
+			MinDelay: 90 * time.Millisecond,
+			MaxDelay: 200 * time.Millisecond,
+		},
+		{
+			Name:     "Leaky bucket ignores burst",
+			Options:  RateLimitOptions{Rate: 20, Burst: 5, Leaky: true},
+			Urls:     []string{"http://a", "http://a", "http://a"},
+			MinDelay: 90 * time.Millisecond,
+			MaxDelay: 200 * time.Millisecond,
+		},
+		{
+			Name:     "Separate buckets per host",
+			Options:  RateLimitOptions{Rate: 1, Burst: 1},
+			Urls:     []string{"http://a", "http://b", "http://c"},
+			MinDelay: 0,
+			MaxDelay: 50 * time.Millisecond,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{StatusCode: 200}
+			cl := NewClient(mock)
+			cl.Use(RateLimit(test.Options))
+
+			now := time.Now()
+			for _, url := range test.Urls {
+				_, err := cl.Get(url)
+				assert.Nil(t, err)
+			}
+			elapsed := time.Since(now)
+
+			assert.Equal(t, len(test.Urls), mock.CountCalled)
+			assert.GreaterOrEqual(t, elapsed, test.MinDelay)
+			assert.LessOrEqual(t, elapsed, test.MaxDelay)
+		})
+	}
+}
+
+// TestRateLimitDeadline tests failing requests that would wait beyond the
+// deadline of their context.
+func TestRateLimitDeadline(t *testing.T) {
+	tests := []struct {
+		Name string
+	}{
+		{Name: "Wait exceeds deadline"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{StatusCode: 200}
+			cl := NewClient(mock)
+			cl.Use(RateLimit(RateLimitOptions{Rate: 1, Burst: 1}))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			req1, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://a", nil)
+			req2, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://a", nil)
+
+			_, err1 := cl.Do(req1)
+			_, err2 := cl.Do(req2)
+
+			assert.Nil(t, err1)
+			assert.ErrorIs(t, err2, ErrRateLimited)
+			assert.Equal(t, 1, mock.CountCalled)
+		})
+	}
+}
+
+// TestRateLimitHeaders tests adjusting buckets from response headers.
+func TestRateLimitHeaders(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Remaining string
+		Reset     func() string
+		MinDelay  time.Duration
+		MaxDelay  time.Duration
+	}{
+		{
+			Name:      "Remaining requests",
+			Remaining: "5",
+			Reset:     func() string { return "" },
+			MinDelay:  0,
+			MaxDelay:  50 * time.Millisecond,
+		},
+		{
+			Name:      "No remaining requests with reset in seconds",
+			Remaining: "0",
+			Reset:     func() string { return "0.1" },
+			MinDelay:  90 * time.Millisecond,
+			MaxDelay:  200 * time.Millisecond,
+		},
+		{
+			Name:      "No remaining requests with reset timestamp",
+			Remaining: "0",
+			Reset: func() string {
+				reset := time.Now().Add(100 * time.Millisecond)
+				return strconv.FormatFloat(float64(reset.UnixMilli())/1000, 'f', 3, 64)
+			},
+			MinDelay: 80 * time.Millisecond,
+			MaxDelay: 200 * time.Millisecond,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{
+				StatusCode: 200,
+				Header: http.Header{
+					"X-Ratelimit-Remaining": {test.Remaining},
+					"X-Ratelimit-Reset":     {test.Reset()},
+				},
+			}
+			cl := NewClient(mock)
+			cl.Use(RateLimit(RateLimitOptions{Rate: 1000, Burst: 10}))
+
+			now := time.Now()
+			cl.Get("http://a")
+			cl.Get("http://a")
+			elapsed := time.Since(now)
+
+			assert.GreaterOrEqual(t, elapsed, test.MinDelay)
+			assert.LessOrEqual(t, elapsed, test.MaxDelay)
+		})
+	}
+}
+
+// TestRateLimitByRoute tests identifying buckets by route.
+func TestRateLimitByRoute(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Builder *RequestBuilder
+		Key     string
+	}{
+		{
+			Name: "Request with format",
+			Builder: NewRequest(
+				http.MethodGet, "https://localhost:8080/users/{}?expand=true",
+			).WithPathParameters("123"),
+			Key: "GET localhost:8080/users/{}",
+		},
+		{
+			Name:    "Request with format without path",
+			Builder: NewRequest(http.MethodPost, "https://localhost:8080"),
+			Key:     "POST localhost:8080/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req, _ := test.Builder.Build(context.Background())
+			ctx := newRequestContext(&mockRequester{}, req, nil)
+
+			assert.Equal(t, test.Key, RateLimitByRoute(ctx))
+		})
+	}
+}
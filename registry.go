@@ -0,0 +1,151 @@
+package gent
+
+import (
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Codec pairs the marshaler and unmarshaler of a media type. Either of them
+// can be nil if the media type is only used for requests or responses.
+type Codec struct {
+	Marshaler   Marshaler
+	Unmarshaler Unmarshaler
+
+	// Quality is the q-value of the media type in generated Accept headers,
+	// between 0 and 1. Zero defaults to 1.
+	Quality float64
+}
+
+// Registry maps media types to marshalers and unmarshalers, which are used to
+// encode request bodies by their Content-Type, decode response bodies by their
+// Content-Type, and to generate Accept headers. It is safe for concurrent use.
+type Registry struct {
+	mtx    sync.RWMutex
+	codecs map[string]Codec
+	order  []string
+}
+
+// DefaultRegistry is the registry used by clients without a registry. It has
+// codecs for application/json, application/xml, text/xml and
+// application/x-www-form-urlencoded.
+var DefaultRegistry = newDefaultRegistry()
+
+// newDefaultRegistry creates a registry with the codecs of the package.
+func newDefaultRegistry() *Registry {
+	reg := NewRegistry()
+	reg.Register("application/json", Codec{
+		Marshaler:   JsonMarshaler,
+		Unmarshaler: JsonUnmarshaler,
+	})
+	reg.Register("application/xml", Codec{
+		Marshaler:   XmlMarshaler,
+		Unmarshaler: XmlUnmarshaler,
+		Quality:     0.9,
+	})
+	reg.Register("text/xml", Codec{
+		Unmarshaler: XmlUnmarshaler,
+		Quality:     0.8,
+	})
+	reg.Register("application/x-www-form-urlencoded", Codec{
+		Marshaler:   UrlEncodedMarshaler,
+		Unmarshaler: UrlEncodedUnmarshaler,
+		Quality:     0.5,
+	})
+	return reg
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{codecs: map[string]Codec{}}
+}
+
+// Register adds the codec of a media type to the registry. If there was
+// already a codec registered for the media type, it will overwrite it.
+func (r *Registry) Register(mediaType string, codec Codec) {
+	mediaType = strings.ToLower(mediaType)
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if _, ok := r.codecs[mediaType]; !ok {
+		r.order = append(r.order, mediaType)
+	}
+	r.codecs[mediaType] = codec
+}
+
+// Marshaler returns the marshaler of the media type of a Content-Type header,
+// or nil if there is none.
+func (r *Registry) Marshaler(contentType string) Marshaler {
+	codec, _ := r.lookup(contentType)
+	return codec.Marshaler
+}
+
+// Unmarshaler returns the unmarshaler of the media type of a Content-Type
+// header, or nil if there is none. Structured syntax suffixes such as
+// application/problem+json fall back to the unmarshaler of application/json
+// or application/xml.
+func (r *Registry) Unmarshaler(contentType string) Unmarshaler {
+	codec, media := r.lookup(contentType)
+	if codec.Unmarshaler != nil {
+		return codec.Unmarshaler
+	}
+
+	if _, suffix, ok := strings.Cut(media, "+"); ok {
+		codec, _ = r.lookup("application/" + suffix)
+	}
+	return codec.Unmarshaler
+}
+
+// Accept returns the value of an Accept header with the media types that
+// have an unmarshaler, ordered by their quality and the order they were
+// registered in.
+func (r *Registry) Accept() string {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+
+	types := make([]string, 0, len(r.order))
+	for _, media := range r.order {
+		if r.codecs[media].Unmarshaler != nil {
+			types = append(types, media)
+		}
+	}
+	sort.SliceStable(types, func(i, j int) bool {
+		return r.codecs[types[i]].quality() > r.codecs[types[j]].quality()
+	})
+
+	var sb strings.Builder
+	for i, media := range types {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(media)
+		if q := r.codecs[media].quality(); q < 1 {
+			sb.WriteString(";q=")
+			sb.WriteString(strconv.FormatFloat(q, 'f', -1, 64))
+		}
+	}
+	return sb.String()
+}
+
+// lookup returns the codec and the media type of a Content-Type header.
+func (r *Registry) lookup(contentType string) (Codec, string) {
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Codec{}, ""
+	}
+
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	return r.codecs[media], media
+}
+
+// quality returns the q-value of the codec.
+func (c Codec) quality() float64 {
+	if c.Quality <= 0 || c.Quality > 1 {
+		return 1
+	}
+	return c.Quality
+}
@@ -0,0 +1,125 @@
+package gent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRegistryLookup tests finding marshalers and unmarshalers by media type.
+func TestRegistryLookup(t *testing.T) {
+	tests := []struct {
+		Name        string
+		ContentType string
+		Marshaler   bool
+		Unmarshaler bool
+	}{
+		{
+			Name:        "Exact media type",
+			ContentType: "application/json",
+			Marshaler:   true,
+			Unmarshaler: true,
+		},
+		{
+			Name:        "Media type with parameters",
+			ContentType: "Application/JSON; charset=utf-8",
+			Marshaler:   true,
+			Unmarshaler: true,
+		},
+		{
+			Name:        "Decode only media type",
+			ContentType: "text/xml",
+			Marshaler:   false,
+			Unmarshaler: true,
+		},
+		{
+			Name:        "Json structured suffix",
+			ContentType: "application/problem+json",
+			Marshaler:   false,
+			Unmarshaler: true,
+		},
+		{
+			Name:        "Xml structured suffix",
+			ContentType: "application/atom+xml",
+			Marshaler:   false,
+			Unmarshaler: true,
+		},
+		{
+			Name:        "Unknown media type",
+			ContentType: "application/octet-stream",
+			Marshaler:   false,
+			Unmarshaler: false,
+		},
+		{
+			Name:        "Invalid media type",
+			ContentType: "",
+			Marshaler:   false,
+			Unmarshaler: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			reg := newDefaultRegistry()
+
+			assert.Equal(t, test.Marshaler, reg.Marshaler(test.ContentType) != nil)
+			assert.Equal(t, test.Unmarshaler, reg.Unmarshaler(test.ContentType) != nil)
+		})
+	}
+}
+
+// TestRegistryAccept tests generating Accept headers from the registry.
+func TestRegistryAccept(t *testing.T) {
+	unmarshaler := func(data []byte, v any) error { return nil }
+
+	tests := []struct {
+		Name   string
+		Codecs map[string]Codec
+		Order  []string
+		Accept string
+	}{
+		{
+			Name:   "Empty registry",
+			Accept: "",
+		},
+		{
+			Name: "Ordered by quality",
+			Codecs: map[string]Codec{
+				"application/xml":  {Unmarshaler: unmarshaler, Quality: 0.5},
+				"application/cbor": {Unmarshaler: unmarshaler, Quality: 0.75},
+				"application/json": {Unmarshaler: unmarshaler},
+			},
+			Order:  []string{"application/xml", "application/cbor", "application/json"},
+			Accept: "application/json, application/cbor;q=0.75, application/xml;q=0.5",
+		},
+		{
+			Name: "Equal quality in registration order",
+			Codecs: map[string]Codec{
+				"application/msgpack": {Unmarshaler: unmarshaler},
+				"application/json":    {Unmarshaler: unmarshaler},
+			},
+			Order:  []string{"application/msgpack", "application/json"},
+			Accept: "application/msgpack, application/json",
+		},
+		{
+			Name: "Encode only media type",
+			Codecs: map[string]Codec{
+				"application/json": {Unmarshaler: unmarshaler},
+				"text/plain":       {Marshaler: UrlEncodedMarshaler},
+			},
+			Order:  []string{"application/json", "text/plain"},
+			Accept: "application/json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			reg := NewRegistry()
+			for _, media := range test.Order {
+				reg.Register(media, test.Codecs[media])
+			}
+
+			assert.Equal(t, test.Accept, reg.Accept())
+		})
+	}
+}
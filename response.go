@@ -0,0 +1,134 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrUnsupportedContentType is returned when a response body can not be
+// decoded because there is no unmarshaler for its Content-Type header.
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// maxErrorBodySize is the maximum number of bytes of the response body kept
+// in an HTTPError.
+const maxErrorBodySize = 4 << 10
+
+// HTTPError is returned when a response has an unexpected status code. It
+// describes the request and keeps the start of the response body.
+type HTTPError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// newHTTPError creates an HTTPError from a request and its response, and
+// reads at most limit bytes of the response body.
+func newHTTPError(
+	req *http.Request,
+	res *http.Response,
+	limit int64,
+) *HTTPError {
+	err := &HTTPError{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+	}
+	if req != nil {
+		err.Method = req.Method
+		err.URL = req.URL.String()
+	}
+	if res.Body != nil && limit > 0 {
+		err.Body, _ = io.ReadAll(io.LimitReader(res.Body, limit))
+	}
+	return err
+}
+
+// Error returns the method, URL and status of the response as a string.
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf(
+		"%s %s: unexpected status %d %s",
+		e.Method, e.URL, e.StatusCode, http.StatusText(e.StatusCode),
+	)
+}
+
+// Decode reads the body of a response into a value of type T with the
+// unmarshaler in the DefaultRegistry matching the response's Content-Type
+// header. The body is drained and closed. Responses with non-2xx status codes
+// return an HTTPError.
+func Decode[T any](res *http.Response) (val T, err error) {
+	unmarshal := DefaultRegistry.Unmarshaler(res.Header.Get("Content-Type"))
+	err = decodeResponse(res.Request, res, &val, unmarshal)
+	return val, err
+}
+
+// DoJSON builds the request, sends it with the client and decodes the json
+// response body into a value of type T. An Accept header is added if the
+// request has none, and bodies without a Content-Type are decoded as json.
+// Responses with non-2xx status codes return an HTTPError.
+func DoJSON[T any](
+	ctx context.Context,
+	cl *Client,
+	rb *RequestBuilder,
+) (val T, err error) {
+	req, err := rb.Build(ctx)
+	if err != nil {
+		return val, err
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+
+	res, err := cl.Do(req)
+	if err != nil {
+		if res != nil && res.Body != nil {
+			res.Body.Close()
+		}
+		return val, err
+	}
+
+	unmarshal := JsonUnmarshaler
+	if ctype := res.Header.Get("Content-Type"); ctype != "" {
+		unmarshal = cl.codecs().Unmarshaler(ctype)
+	}
+
+	err = decodeResponse(req, res, &val, unmarshal)
+	return val, err
+}
+
+// decodeResponse reads the body of the response into v with the unmarshaler.
+// The body is drained and closed.
+func decodeResponse(
+	req *http.Request,
+	res *http.Response,
+	v any,
+	unmarshal Unmarshaler,
+) error {
+	if res.Body != nil {
+		defer res.Body.Close()
+		defer io.Copy(io.Discard, res.Body)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return newHTTPError(req, res, maxErrorBodySize)
+	}
+
+	var data []byte
+	if res.Body != nil {
+		var err error
+		if data, err = io.ReadAll(res.Body); err != nil {
+			return err
+		}
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	if unmarshal == nil {
+		return ErrUnsupportedContentType
+	}
+	return unmarshal(data, v)
+}
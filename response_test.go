@@ -0,0 +1,213 @@
+package gent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Id   int    `json:"id" xml:"id"`
+	Name string `json:"name" xml:"name"`
+}
+
+// TestDecode tests decoding response bodies.
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Response *http.Response
+		Value    user
+		Error    error
+	}{
+		{
+			Name: "Decode json",
+			Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+				Body:       io.NopCloser(strings.NewReader(`{"id":1,"name":"John Smith"}`)),
+			},
+			Value: user{Id: 1, Name: "John Smith"},
+			Error: nil,
+		},
+		{
+			Name: "Decode xml",
+			Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"application/xml"}},
+				Body: io.NopCloser(strings.NewReader(
+					`<user><id>1</id><name>John Smith</name></user>`,
+				)),
+			},
+			Value: user{Id: 1, Name: "John Smith"},
+			Error: nil,
+		},
+		{
+			Name: "Empty body",
+			Response: &http.Response{
+				StatusCode: 204,
+				Header:     http.Header{},
+				Body:       http.NoBody,
+			},
+			Value: user{},
+			Error: nil,
+		},
+		{
+			Name: "Unsupported content type",
+			Response: &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": {"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader(`John Smith`)),
+			},
+			Value: user{},
+			Error: ErrUnsupportedContentType,
+		},
+		{
+			Name: "Unexpected status code",
+			Response: &http.Response{
+				StatusCode: 404,
+				Header:     http.Header{"Content-Type": {"text/plain"}},
+				Body:       io.NopCloser(strings.NewReader(`not found`)),
+				Request: &http.Request{
+					Method: http.MethodGet,
+					URL:    mustParseURL("http://localhost:8080/users/1"),
+				},
+			},
+			Value: user{},
+			Error: &HTTPError{
+				Method:     http.MethodGet,
+				URL:        "http://localhost:8080/users/1",
+				StatusCode: 404,
+				Header:     http.Header{"Content-Type": {"text/plain"}},
+				Body:       []byte("not found"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			val, err := Decode[user](test.Response)
+
+			assert.Equal(t, test.Error, err)
+			assert.Equal(t, test.Value, val)
+		})
+	}
+}
+
+// TestDoJSON tests sending requests and decoding their json responses.
+func TestDoJSON(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Requester *mockRequester
+		Value     user
+		Status    int
+		Error     bool
+	}{
+		{
+			Name: "Successful request",
+			Requester: &mockRequester{
+				StatusCode: 200,
+				Body:       []byte(`{"id":1,"name":"John Smith"}`),
+			},
+			Value: user{Id: 1, Name: "John Smith"},
+		},
+		{
+			Name: "Unexpected status code",
+			Requester: &mockRequester{
+				StatusCode: 500,
+				Body:       []byte(`{"error":"internal"}`),
+			},
+			Status: 500,
+			Error:  true,
+		},
+		{
+			Name: "Request failed",
+			Requester: &mockRequester{
+				RequestErr: fmt.Errorf("failed"),
+			},
+			Error: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cl := NewClient(test.Requester)
+			rb := NewRequest(http.MethodGet, "http://localhost:8080/users/{}").
+				WithPathParameters("1")
+
+			val, err := DoJSON[user](context.Background(), cl, rb)
+
+			assert.Equal(t, test.Value, val)
+			if test.Error {
+				assert.NotNil(t, err)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, "application/json", test.Requester.LastRequest.Header.Get("Accept"))
+			}
+
+			var herr *HTTPError
+			if test.Status != 0 && assert.True(t, errors.As(err, &herr)) {
+				assert.Equal(t, test.Status, herr.StatusCode)
+				assert.Equal(t, http.MethodGet, herr.Method)
+				assert.Equal(t, "http://localhost:8080/users/1", herr.URL)
+				assert.Equal(t, test.Requester.Body, herr.Body)
+			}
+		})
+	}
+}
+
+// TestHTTPError tests creating and formatting HTTP errors.
+func TestHTTPError(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Request *http.Request
+		Body    []byte
+		Limit   int64
+		Snippet []byte
+		Message string
+	}{
+		{
+			Name: "Body within limit",
+			Request: &http.Request{
+				Method: http.MethodPost,
+				URL:    mustParseURL("http://localhost:8080/users"),
+			},
+			Body:    []byte("bad request"),
+			Limit:   100,
+			Snippet: []byte("bad request"),
+			Message: "POST http://localhost:8080/users: unexpected status 400 Bad Request",
+		},
+		{
+			Name: "Body over limit",
+			Request: &http.Request{
+				Method: http.MethodPost,
+				URL:    mustParseURL("http://localhost:8080/users"),
+			},
+			Body:    []byte("bad request"),
+			Limit:   3,
+			Snippet: []byte("bad"),
+			Message: "POST http://localhost:8080/users: unexpected status 400 Bad Request",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			res := &http.Response{
+				StatusCode: 400,
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewReader(test.Body)),
+			}
+
+			err := newHTTPError(test.Request, res, test.Limit)
+
+			assert.Equal(t, test.Snippet, err.Body)
+			assert.Equal(t, test.Message, err.Error())
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryOptions configures the behavior of the Retry middleware.
+type RetryOptions struct {
+	// MaxAttempts is the maximum number of times the request is sent,
+	// including the first attempt. Defaults to 3.
+	MaxAttempts int
+
+	// BaseDelay is the delay before the first retry, which doubles after
+	// every attempt. Defaults to 100 milliseconds.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between attempts. If the server asks for a
+	// longer delay with a Retry-After header, the request is not retried.
+	// Defaults to 30 seconds.
+	MaxDelay time.Duration
+
+	// Retryable optionally reports whether an attempt should be retried when
+	// it did not fail with a transport error or a retryable status code.
+	Retryable func(*Context) bool
+}
+
+// retryStatusCodes are the response status codes that are always retried.
+var retryStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// Retry creates a middleware that runs the rest of the chain again when an
+// attempt fails with a transport error, a 429, 502, 503 or 504 status code,
+// or when the user supplied predicate returns true. Attempts are delayed with
+// exponential backoff and jitter, or as long as the Retry-After header asks.
+// The request body is rewound between attempts, and retrying stops when the
+// request's context is done.
+func Retry(opts RetryOptions) func(*Context) {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = 100 * time.Millisecond
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = 30 * time.Second
+	}
+
+	return func(ctx *Context) {
+		req, errc := ctx.Request, len(ctx.Errors)
+		for attempt := 1; ; attempt++ {
+			ctx.Next()
+
+			if attempt >= opts.MaxAttempts || ctx.IsAborted() ||
+				!opts.retryable(ctx, errc) {
+				return
+			}
+
+			delay := backoff(opts.BaseDelay, opts.MaxDelay, attempt)
+			if ctx.Response != nil {
+				if after, ok := retryAfter(ctx.Response.Header); ok {
+					if after > opts.MaxDelay {
+						return
+					} else if after > delay {
+						delay = after
+					}
+				}
+			}
+
+			ctx.Request = req
+			if ctx.RewindBody() != nil || !wait(req.Context(), delay) {
+				return
+			}
+
+			if ctx.Response != nil && ctx.Response.Body != nil {
+				ctx.Response.Body.Close()
+			}
+			ctx.Response = nil
+			ctx.Errors = ctx.Errors[:errc]
+		}
+	}
+}
+
+// retryable reports whether the last attempt on the context should be
+// retried. Errors after the index errc were added by the last attempt. An
+// *HTTPError is not retryable by itself, as its status code decides instead.
+func (opts *RetryOptions) retryable(ctx *Context, errc int) bool {
+	for _, err := range ctx.Errors[errc:] {
+		var herr *HTTPError
+		if !errors.As(err, &herr) {
+			return true
+		}
+	}
+
+	if ctx.Response != nil && retryStatusCodes[ctx.Response.StatusCode] {
+		return true
+	} else if opts.Retryable != nil {
+		return opts.Retryable(ctx)
+	}
+	return false
+}
+
+// backoff returns the delay before the next attempt with exponential backoff
+// and jitter, where the delay is randomized between half and all of its value.
+func backoff(base, limit time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// retryAfter parses the Retry-After header, which is either a number of
+// seconds or an HTTP date.
+func retryAfter(hdr http.Header) (time.Duration, bool) {
+	val := hdr.Get("Retry-After")
+	if val == "" {
+		return 0, false
+	}
+
+	if secs, err := strconv.Atoi(val); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	} else if date, err := http.ParseTime(val); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// wait blocks for the duration of the delay, or until the context is done. It
+// returns false if the context is done.
+func wait(ctx context.Context, delay time.Duration) bool {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,342 @@
+package gent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRetry tests retrying requests with the retry middleware.
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Options   RetryOptions
+		Requester *mockRequester
+		Attempts  int
+		Status    int
+		Error     bool
+	}{
+		{
+			Name:      "Successful first attempt",
+			Options:   RetryOptions{BaseDelay: time.Millisecond},
+			Requester: &mockRequester{StatusCode: 200},
+			Attempts:  1,
+			Status:    200,
+		},
+		{
+			Name:      "Retryable status code then success",
+			Options:   RetryOptions{BaseDelay: time.Millisecond},
+			Requester: &mockRequester{StatusCodes: []int{503, 502, 200}},
+			Attempts:  3,
+			Status:    200,
+		},
+		{
+			Name:      "Non retryable status code",
+			Options:   RetryOptions{BaseDelay: time.Millisecond},
+			Requester: &mockRequester{StatusCodes: []int{500, 200}},
+			Attempts:  1,
+			Status:    500,
+		},
+		{
+			Name: "Retryable by predicate",
+			Options: RetryOptions{
+				BaseDelay: time.Millisecond,
+				Retryable: func(ctx *Context) bool {
+					return ctx.Response.StatusCode == 500
+				},
+			},
+			Requester: &mockRequester{StatusCodes: []int{500, 200}},
+			Attempts:  2,
+			Status:    200,
+		},
+		{
+			Name:      "Attempts exhausted",
+			Options:   RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond},
+			Requester: &mockRequester{StatusCode: 504},
+			Attempts:  2,
+			Status:    504,
+		},
+		{
+			Name:      "Transport error",
+			Options:   RetryOptions{MaxAttempts: 4, BaseDelay: time.Millisecond},
+			Requester: &mockRequester{RequestErr: fmt.Errorf("failed")},
+			Attempts:  4,
+			Error:     true,
+		},
+		{
+			Name:    "Retry-After longer than max delay",
+			Options: RetryOptions{BaseDelay: time.Millisecond, MaxDelay: time.Second},
+			Requester: &mockRequester{
+				StatusCode: 429,
+				Header:     http.Header{"Retry-After": {"120"}},
+			},
+			Attempts: 1,
+			Status:   429,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cl := NewClient(test.Requester)
+			cl.Use(Retry(test.Options))
+
+			body := []byte(`{"name":"John Smith"}`)
+			req, _ := http.NewRequest(
+				http.MethodPost, "http://localhost:8080", bytes.NewReader(body),
+			)
+			res, err := cl.Do(req)
+
+			assert.Equal(t, test.Attempts, test.Requester.CountCalled)
+			for _, b := range test.Requester.Bodies {
+				assert.Equal(t, body, b)
+			}
+			if test.Error {
+				assert.NotNil(t, err)
+				assert.Nil(t, res)
+			} else {
+				assert.Nil(t, err)
+				assert.Equal(t, test.Status, res.StatusCode)
+			}
+		})
+	}
+}
+
+// TestRetryStatusCheck tests retrying requests with the StatusCheck
+// middleware after the retry middleware.
+func TestRetryStatusCheck(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Requester *mockRequester
+		Attempts  int
+		Status    int
+	}{
+		{
+			Name:      "Non retryable status code",
+			Requester: &mockRequester{StatusCode: 404},
+			Attempts:  1,
+			Status:    404,
+		},
+		{
+			Name:      "Retryable status code",
+			Requester: &mockRequester{StatusCode: 503},
+			Attempts:  3,
+			Status:    503,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			cl := NewClient(test.Requester)
+			cl.Use(
+				Retry(RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+				StatusCheck(StatusCheckOptions{}),
+			)
+
+			res, err := cl.Get("http://localhost:8080")
+
+			var herr *HTTPError
+			assert.ErrorAs(t, err, &herr)
+			assert.Equal(t, test.Status, herr.StatusCode)
+			assert.Equal(t, test.Status, res.StatusCode)
+			assert.Equal(t, test.Attempts, test.Requester.CountCalled)
+		})
+	}
+}
+
+// TestRetryTimeout tests retrying requests with a timeout for every attempt.
+func TestRetryTimeout(t *testing.T) {
+	tests := []struct {
+		Name      string
+		Requester *mockRequester
+		Attempts  int
+		Status    int
+	}{
+		{
+			Name:      "Retryable status code then success",
+			Requester: &mockRequester{StatusCodes: []int{503, 502, 200}},
+			Attempts:  3,
+			Status:    200,
+		},
+		{
+			Name:      "Attempts exhausted",
+			Requester: &mockRequester{StatusCode: 503},
+			Attempts:  3,
+			Status:    503,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var errs []error
+			cl := NewClient(test.Requester)
+			cl.Use(
+				Retry(RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond}),
+				func(ctx *Context) {
+					ctx.WithTimeout(time.Second)
+					ctx.Next()
+				},
+				func(ctx *Context) {
+					errs = append(errs, ctx.Err())
+					ctx.Next()
+				},
+			)
+
+			req, _ := http.NewRequest(http.MethodGet, "http://localhost:8080", nil)
+			res, err := cl.Do(req)
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.Status, res.StatusCode)
+			assert.Equal(t, test.Attempts, test.Requester.CountCalled)
+			assert.Equal(t, make([]error, test.Attempts), errs)
+			assert.Nil(t, req.Context().Err())
+		})
+	}
+}
+
+// TestRetryContextDone tests that retrying stops when the request's
+// context is done.
+func TestRetryContextDone(t *testing.T) {
+	tests := []struct {
+		Name string
+	}{
+		{Name: "Context canceled"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{StatusCode: 503}
+			cl := NewClient(mock)
+			cl.Use(Retry(RetryOptions{BaseDelay: time.Hour, MaxDelay: time.Hour}))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8080", nil)
+			res, err := cl.Do(req)
+
+			assert.Nil(t, err)
+			assert.Equal(t, 503, res.StatusCode)
+			assert.Equal(t, 1, mock.CountCalled)
+		})
+	}
+}
+
+// TestRetryAborted tests that retrying stops when the chain is aborted.
+func TestRetryAborted(t *testing.T) {
+	tests := []struct {
+		Name string
+	}{
+		{Name: "Circuit opened"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{StatusCode: 503}
+			cl := NewClient(mock)
+			cl.Use(
+				Retry(RetryOptions{BaseDelay: time.Millisecond}),
+				CircuitBreaker(CircuitBreakerOptions{
+					FailureThreshold: 1,
+					OpenTimeout:      time.Hour,
+				}),
+			)
+
+			_, err := cl.Get("http://localhost:8080")
+
+			assert.ErrorIs(t, err, ErrCircuitOpen)
+			assert.Equal(t, 1, mock.CountCalled)
+		})
+	}
+}
+
+// TestBackoff tests the delays calculated between attempts.
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Base    time.Duration
+		Max     time.Duration
+		Attempt int
+		Lower   time.Duration
+		Upper   time.Duration
+	}{
+		{
+			Name:    "First attempt",
+			Base:    100 * time.Millisecond,
+			Max:     time.Second,
+			Attempt: 1,
+			Lower:   50 * time.Millisecond,
+			Upper:   100 * time.Millisecond,
+		},
+		{
+			Name:    "Third attempt",
+			Base:    100 * time.Millisecond,
+			Max:     time.Second,
+			Attempt: 3,
+			Lower:   200 * time.Millisecond,
+			Upper:   400 * time.Millisecond,
+		},
+		{
+			Name:    "Capped by max delay",
+			Base:    100 * time.Millisecond,
+			Max:     time.Second,
+			Attempt: 10,
+			Lower:   500 * time.Millisecond,
+			Upper:   time.Second,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			delay := backoff(test.Base, test.Max, test.Attempt)
+
+			assert.GreaterOrEqual(t, delay, test.Lower)
+			assert.LessOrEqual(t, delay, test.Upper)
+		})
+	}
+}
+
+// TestRetryAfter tests parsing the Retry-After header.
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		Name   string
+		Header http.Header
+		Delay  time.Duration
+		Ok     bool
+	}{
+		{
+			Name:   "Missing header",
+			Header: http.Header{},
+			Ok:     false,
+		},
+		{
+			Name:   "Delay in seconds",
+			Header: http.Header{"Retry-After": {"3"}},
+			Delay:  3 * time.Second,
+			Ok:     true,
+		},
+		{
+			Name:   "Date in the past",
+			Header: http.Header{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}},
+			Delay:  0,
+			Ok:     true,
+		},
+		{
+			Name:   "Invalid value",
+			Header: http.Header{"Retry-After": {"soon"}},
+			Ok:     false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			delay, ok := retryAfter(test.Header)
+
+			assert.Equal(t, test.Ok, ok)
+			assert.Equal(t, test.Delay, delay)
+		})
+	}
+}
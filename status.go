@@ -0,0 +1,75 @@
+package gent
+
+import (
+	"bytes"
+	"io"
+)
+
+// StatusRange is an inclusive range of response status codes.
+type StatusRange struct {
+	From int
+	To   int
+}
+
+// Contains reports whether the status code is within the range.
+func (sr StatusRange) Contains(code int) bool {
+	return code >= sr.From && code <= sr.To
+}
+
+// StatusCheckOptions configures the behavior of the StatusCheck middleware.
+type StatusCheckOptions struct {
+	// Accepted are the ranges of status codes that are not errors. Defaults
+	// to the 2xx status codes.
+	Accepted []StatusRange
+
+	// MaxBodySize is the maximum number of bytes of the response body kept
+	// in the error. Defaults to 4 KiB.
+	MaxBodySize int64
+}
+
+// StatusCheck creates a middleware that adds an *HTTPError to the context
+// when the response has an unexpected status code. The accepted status codes
+// can be overridden per request with RequestBuilder.WithAcceptedStatus. The
+// response is still returned with its body intact.
+func StatusCheck(opts StatusCheckOptions) func(*Context) {
+	if len(opts.Accepted) == 0 {
+		opts.Accepted = []StatusRange{{From: 200, To: 299}}
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = maxErrorBodySize
+	}
+
+	return func(ctx *Context) {
+		ctx.Next()
+
+		res := ctx.Response
+		if res == nil {
+			return
+		}
+
+		accepted := opts.Accepted
+		if meta := getRequestMeta(ctx.Request); meta != nil && meta.accepted != nil {
+			accepted = meta.accepted
+		}
+		for _, sr := range accepted {
+			if sr.Contains(res.StatusCode) {
+				return
+			}
+		}
+
+		err := newHTTPError(ctx.Request, res, opts.MaxBodySize)
+		if res.Body != nil {
+			res.Body = &readCloser{
+				Reader: io.MultiReader(bytes.NewReader(err.Body), res.Body),
+				Closer: res.Body,
+			}
+		}
+		ctx.Error(err)
+	}
+}
+
+// readCloser combines a reader and a closer of different sources.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,105 @@
+package gent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStatusCheck tests adding errors for unexpected status codes.
+func TestStatusCheck(t *testing.T) {
+	tests := []struct {
+		Name    string
+		Options StatusCheckOptions
+		Builder *RequestBuilder
+		Status  int
+		Body    []byte
+		Error   bool
+		Snippet []byte
+	}{
+		{
+			Name:    "Accepted status code",
+			Options: StatusCheckOptions{},
+			Builder: NewRequest(http.MethodGet, "http://localhost:8080"),
+			Status:  201,
+			Error:   false,
+		},
+		{
+			Name:    "Unexpected status code",
+			Options: StatusCheckOptions{},
+			Builder: NewRequest(http.MethodGet, "http://localhost:8080"),
+			Status:  500,
+			Body:    []byte("internal server error"),
+			Error:   true,
+			Snippet: []byte("internal server error"),
+		},
+		{
+			Name:    "Body snippet over limit",
+			Options: StatusCheckOptions{MaxBodySize: 8},
+			Builder: NewRequest(http.MethodGet, "http://localhost:8080"),
+			Status:  500,
+			Body:    []byte("internal server error"),
+			Error:   true,
+			Snippet: []byte("internal"),
+		},
+		{
+			Name: "Status code accepted by client",
+			Options: StatusCheckOptions{
+				Accepted: []StatusRange{{From: 200, To: 299}, {From: 404, To: 404}},
+			},
+			Builder: NewRequest(http.MethodGet, "http://localhost:8080"),
+			Status:  404,
+			Error:   false,
+		},
+		{
+			Name:    "Status code accepted by request",
+			Options: StatusCheckOptions{},
+			Builder: NewRequest(http.MethodGet, "http://localhost:8080").
+				WithAcceptedStatus(StatusRange{From: 404, To: 404}),
+			Status: 404,
+			Error:  false,
+		},
+		{
+			Name:    "Status code not accepted by request",
+			Options: StatusCheckOptions{},
+			Builder: NewRequest(http.MethodGet, "http://localhost:8080").
+				WithAcceptedStatus(StatusRange{From: 404, To: 404}),
+			Status:  200,
+			Body:    []byte("{}"),
+			Error:   true,
+			Snippet: []byte("{}"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			mock := &mockRequester{StatusCode: test.Status, Body: test.Body}
+			cl := NewClient(mock)
+			cl.Use(StatusCheck(test.Options))
+
+			req, _ := test.Builder.Build(context.Background())
+			res, err := cl.Do(req)
+
+			assert.NotNil(t, res)
+			if !test.Error {
+				assert.Nil(t, err)
+				return
+			}
+
+			var herr *HTTPError
+			if assert.True(t, errors.As(err, &herr)) {
+				assert.Equal(t, test.Status, herr.StatusCode)
+				assert.Equal(t, http.MethodGet, herr.Method)
+				assert.Equal(t, "http://localhost:8080", herr.URL)
+				assert.Equal(t, test.Snippet, herr.Body)
+			}
+
+			body, _ := io.ReadAll(res.Body)
+			assert.Equal(t, string(test.Body), string(body))
+		})
+	}
+}
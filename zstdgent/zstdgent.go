@@ -0,0 +1,27 @@
+// Package zstdgent provides the zstd content encoding for gent.
+package zstdgent
+
+import (
+	"io"
+
+	"github.com/Soreing/gent"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Name is the name of the zstd content encoding.
+const Name = "zstd"
+
+// Encoding compresses and decompresses bodies with zstd. Register it with
+// gent.RegisterEncoding under Name.
+var Encoding = gent.Encoding{
+	Compress: func(w io.Writer) (io.WriteCloser, error) {
+		return zstd.NewWriter(w)
+	},
+	Decompress: func(r io.Reader) (io.ReadCloser, error) {
+		dec, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return dec.IOReadCloser(), nil
+	},
+}
@@ -0,0 +1,133 @@
+package zstdgent
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Soreing/gent"
+	"github.com/stretchr/testify/assert"
+)
+
+// reference is the content of a zstd fixture.
+var reference = []byte(strings.Repeat("gent", 16))
+
+// referenceCompressed is the reference content compressed by
+// the zstd command line tool at level 19.
+var referenceCompressed = []byte{
+	0x28, 0xb5, 0x2f, 0xfd, 0x24, 0x40, 0x5d, 0x00,
+	0x00, 0x28, 0x67, 0x65, 0x6e, 0x74, 0x67, 0x01,
+	0x00, 0x20, 0x5d, 0x2d, 0xe7, 0xa8, 0xf9, 0xfa,
+}
+
+// TestEncoding tests compressing and decompressing content.
+func TestEncoding(t *testing.T) {
+	tests := []struct {
+		Name string
+		Data []byte
+	}{
+		{
+			Name: "Empty content",
+			Data: []byte{},
+		},
+		{
+			Name: "Repeated content",
+			Data: []byte(strings.Repeat("gent", 1024)),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			zw, err := Encoding.Compress(&buf)
+			assert.Nil(t, err)
+
+			_, err = zw.Write(test.Data)
+			assert.Nil(t, err)
+			assert.Nil(t, zw.Close())
+			// zstd frames start with a magic number
+			if len(test.Data) > 0 {
+				assert.Equal(t, []byte{0x28, 0xb5, 0x2f, 0xfd}, buf.Bytes()[:4])
+			}
+
+			zr, err := Encoding.Decompress(&buf)
+			assert.Nil(t, err)
+
+			data, err := io.ReadAll(zr)
+			assert.Nil(t, err)
+			assert.Nil(t, zr.Close())
+			assert.Equal(t, test.Data, data)
+		})
+	}
+}
+
+// TestDecompressReference tests decompressing content compressed by another
+// zstd encoder.
+func TestDecompressReference(t *testing.T) {
+	tests := []struct {
+		Name       string
+		Compressed []byte
+		Data       []byte
+	}{
+		{
+			Name:       "Reference content",
+			Compressed: referenceCompressed,
+			Data:       reference,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			zr, err := Encoding.Decompress(bytes.NewReader(test.Compressed))
+			assert.Nil(t, err)
+
+			data, err := io.ReadAll(zr)
+			assert.Nil(t, err)
+			assert.Nil(t, zr.Close())
+			assert.Equal(t, test.Data, data)
+		})
+	}
+}
+
+// TestMiddlewares tests the encoding with the gent middlewares.
+func TestMiddlewares(t *testing.T) {
+	gent.RegisterEncoding(Name, Encoding)
+	data := []byte(strings.Repeat("gent", 1024))
+
+	srv := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, Name, r.Header.Get("Content-Encoding"))
+			assert.Contains(t, r.Header.Get("Accept-Encoding"), Name)
+
+			zr, _ := Encoding.Decompress(r.Body)
+			body, _ := io.ReadAll(zr)
+			assert.Equal(t, data, body)
+
+			w.Header().Set("Content-Encoding", Name)
+			w.Write(referenceCompressed)
+		},
+	))
+	defer srv.Close()
+
+	cl := gent.NewClient(srv.Client())
+	cl.Use(gent.Decompress(gent.DecompressionOptions{}))
+	cl.Use(gent.Compress(gent.CompressionOptions{Encoding: Name}))
+
+	res, err := cl.NewRequest(
+		http.MethodPost, srv.URL,
+	).WithRawBody(
+		data,
+	).Send(context.Background())
+	assert.Nil(t, err)
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+
+	assert.Nil(t, err)
+	assert.Equal(t, reference, body)
+	assert.Equal(t, "", res.Header.Get("Content-Encoding"))
+}